@@ -0,0 +1,99 @@
+package functools
+
+import "sync"
+
+// SlowPolicy decides what happens when a subscriber's buffer is full
+type SlowPolicy int
+
+const (
+	// SlowBlock applies backpressure: the source waits until the slowest subscriber has room.
+	// Memory is bounded by the buffer of each subscriber.
+	SlowBlock SlowPolicy = iota
+	// SlowDropOldest evicts the oldest buffered item to make room for the new one.
+	// Memory is bounded by the buffer of each subscriber.
+	SlowDropOldest
+	// SlowDropNewest discards the incoming item for the full subscriber.
+	// Memory is bounded by the buffer of each subscriber.
+	SlowDropNewest
+	// SlowUnbounded never blocks nor drops, letting a slow subscriber's buffer grow
+	// without limit. Memory is only bounded by how far the subscriber falls behind.
+	SlowUnbounded
+)
+
+// Broadcaster fans a single stream out to every subscriber, applying its SlowPolicy
+// to subscribers that can't keep up
+type Broadcaster[T any] struct {
+	source      *streamable[T]
+	policy      SlowPolicy
+	mu          sync.Mutex
+	subscribers []*queue[T]
+	started     bool
+	finished    bool
+}
+
+// Multicast creates a Broadcaster over s. Register subscribers with Subscribe and
+// then call Start to begin forwarding items.
+func Multicast[T any](s *streamable[T], policy SlowPolicy) *Broadcaster[T] {
+	return &Broadcaster[T]{source: s, policy: policy}
+}
+
+// Subscribe returns a new stream receiving every item forwarded after it subscribed.
+// buffer is how many pending items the subscriber may hold before the policy applies
+// (ignored for SlowUnbounded). Subscribing after the source finished returns a closed stream.
+func (b *Broadcaster[T]) Subscribe(buffer int) *streamable[T] {
+	if buffer < 1 {
+		buffer = 1
+	}
+	if b.policy == SlowUnbounded {
+		buffer = 0
+	}
+	q := newOverflowQueue[T](buffer, b.policy)
+	b.mu.Lock()
+	if b.finished {
+		q.close()
+	} else {
+		b.subscribers = append(b.subscribers, q)
+	}
+	b.mu.Unlock()
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := q.pop()
+			if !ok {
+				return
+			}
+			out <- v
+		}
+	}()
+	return &streamable[T]{stream: out}
+}
+
+// Start begins forwarding source items to the subscribers. Calling it again has no effect.
+func (b *Broadcaster[T]) Start() {
+	b.mu.Lock()
+	if b.started {
+		b.mu.Unlock()
+		return
+	}
+	b.started = true
+	b.mu.Unlock()
+
+	go func() {
+		for v := range b.source.stream {
+			b.mu.Lock()
+			subscribers := b.subscribers
+			b.mu.Unlock()
+			for _, q := range subscribers {
+				q.push(v)
+			}
+		}
+		b.mu.Lock()
+		b.finished = true
+		for _, q := range b.subscribers {
+			q.close()
+		}
+		b.mu.Unlock()
+	}()
+}
@@ -1,9 +1,13 @@
 package functools
 
+import "sort"
+
 // bufferedStream is a collection that processes data on-demand via buffered channels
 type bufferedStream[InputType any] struct {
 	stream     <-chan InputType
 	BufferSize int
+	depth      func() int
+	capacity   func() int
 }
 
 func StreamifyWithBuffer[InputType any](items []InputType, bufferSize int) *bufferedStream[InputType] {
@@ -53,6 +57,11 @@ func (s *bufferedStream[InputType]) Filter(fn func(InputType) bool) *bufferedStr
 	return &bufferedStream[InputType]{stream: out, BufferSize: s.BufferSize}
 }
 
+// FilterNot creates a new streamable keeping only the items for which fn returns false
+func (s *bufferedStream[InputType]) FilterNot(fn func(InputType) bool) *bufferedStream[InputType] {
+	return s.Filter(func(v InputType) bool { return !fn(v) })
+}
+
 // ForEach consumes the stream by applying fn to each item
 func (s *bufferedStream[InputType]) ForEach(fn func(InputType)) {
 	for v := range s.stream {
@@ -69,6 +78,32 @@ func (s *bufferedStream[InputType]) ToSlice() []InputType {
 	return result
 }
 
+// ToSortedSlice collects all items and returns them sorted by less, keeping equal items
+// in arrival order. The stream must be finite, since it is fully drained first.
+func (s *bufferedStream[InputType]) ToSortedSlice(less func(a, b InputType) bool) []InputType {
+	result := s.ToSlice()
+	sort.SliceStable(result, func(i, j int) bool {
+		return less(result[i], result[j])
+	})
+	return result
+}
+
+// Depth reports how many items are currently buffered and waiting for the consumer
+func (s *bufferedStream[InputType]) Depth() int {
+	if s.depth != nil {
+		return s.depth()
+	}
+	return len(s.stream)
+}
+
+// Capacity reports the current effective buffer size of the stream
+func (s *bufferedStream[InputType]) Capacity() int {
+	if s.capacity != nil {
+		return s.capacity()
+	}
+	return cap(s.stream)
+}
+
 // ToStream converts a buffered streamable into a regular streamable (unbuffered channel)
 func (s *bufferedStream[InputType]) ToStream() *streamable[InputType] {
 	ch := make(chan InputType)
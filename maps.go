@@ -0,0 +1,56 @@
+package functools
+
+// MapMapValues returns a new map with the same keys and every value transformed by fn
+func MapMapValues[K comparable, V, R any](m map[K]V, fn func(V) R) map[K]R {
+	result := make(map[K]R, len(m))
+	for k, v := range m {
+		result[k] = fn(v)
+	}
+	return result
+}
+
+// MapMapKeys returns a new map with every key transformed by fn and the same values.
+// When several keys map to the same new key, the last one written wins; since map
+// iteration order is random, which value survives is unspecified.
+func MapMapKeys[K, R comparable, V any](m map[K]V, fn func(K) R) map[R]V {
+	result := make(map[R]V, len(m))
+	for k, v := range m {
+		result[fn(k)] = v
+	}
+	return result
+}
+
+// ToPairs returns the entries of m as an iterable of key/value pairs. Map iteration order
+// is random, so sort the pairs when a deterministic order is needed.
+func ToPairs[K comparable, V any](m map[K]V) *iterable[struct {
+	Key   K
+	Value V
+}] {
+	pairs := make([]struct {
+		Key   K
+		Value V
+	}, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, struct {
+			Key   K
+			Value V
+		}{Key: k, Value: v})
+	}
+	return &iterable[struct {
+		Key   K
+		Value V
+	}]{items: pairs}
+}
+
+// FromPairs builds a map from key/value pairs. When a key appears more than once, the
+// last pair in iteration order wins.
+func FromPairs[K comparable, V any](pairs *iterable[struct {
+	Key   K
+	Value V
+}]) map[K]V {
+	result := make(map[K]V, len(pairs.items))
+	for _, p := range pairs.items {
+		result[p.Key] = p.Value
+	}
+	return result
+}
@@ -0,0 +1,63 @@
+package functools
+
+// PullIterator reads a stream on demand while a background goroutine keeps a buffer of
+// prefetched items filled, bridging channel-based streams and pull-based consumers.
+type PullIterator[T any] struct {
+	buffer chan T
+	done   <-chan struct{}
+	stop   func()
+}
+
+// Prefetch returns a PullIterator that reads ahead up to n items (at least one) so that
+// Next rarely has to wait for the producer. Call Close when stopping before the stream
+// is exhausted: it ends the prefetch goroutine and halts the upstream, so a cancellable
+// producer (see CreateCancellableStream) stops as well.
+func (s *streamable[InputType]) Prefetch(n int) *PullIterator[InputType] {
+	done, stop := newStop()
+	it := &PullIterator[InputType]{
+		buffer: make(chan InputType, max(n, 1)),
+		done:   done,
+		stop: func() {
+			stop()
+			s.halt()
+		},
+	}
+	go func() {
+		defer close(it.buffer)
+		for {
+			select {
+			case <-done:
+				return
+			case v, ok := <-s.stream:
+				if !ok {
+					return
+				}
+				select {
+				case it.buffer <- v:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return it
+}
+
+// Next returns the next item, blocking only when the prefetch buffer is empty.
+// The boolean is false once the stream is exhausted or the iterator has been closed.
+func (it *PullIterator[T]) Next() (T, bool) {
+	select {
+	case <-it.done:
+		var zero T
+		return zero, false
+	default:
+	}
+	v, ok := <-it.buffer
+	return v, ok
+}
+
+// Close stops prefetching without reading the rest of the stream and halts the upstream.
+// It is safe to call more than once.
+func (it *PullIterator[T]) Close() {
+	it.stop()
+}
@@ -0,0 +1,123 @@
+package functools
+
+import "sync"
+
+// queue is a goroutine-safe FIFO used as an elastic buffer between stream stages.
+// A max of 0 or less means the queue is unbounded, otherwise the overflow policy
+// decides what push does when the queue is full.
+type queue[T any] struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    []T
+	max      int
+	overflow SlowPolicy
+	dropped  int
+	closed   bool
+}
+
+// newQueue creates a queue that blocks pushes while full
+func newQueue[T any](max int) *queue[T] {
+	return newOverflowQueue[T](max, SlowBlock)
+}
+
+// newOverflowQueue creates a queue applying overflow when full
+func newOverflowQueue[T any](max int, overflow SlowPolicy) *queue[T] {
+	q := &queue[T]{max: max, overflow: overflow}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push appends v. When the queue is full it blocks (backpressure), evicts the
+// oldest item or drops v, depending on the overflow policy.
+func (q *queue[T]) push(v T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.max > 0 && len(q.items) >= q.max {
+		switch q.overflow {
+		case SlowDropNewest:
+			q.dropped++
+			return
+		case SlowDropOldest:
+			q.dropped++
+			var zero T
+			q.items[0] = zero
+			q.items = q.items[1:]
+		case SlowUnbounded:
+		default:
+			for len(q.items) >= q.max {
+				q.cond.Wait()
+			}
+		}
+	}
+	q.items = append(q.items, v)
+	q.cond.Broadcast()
+}
+
+// tryPush appends v only if the queue has room, reporting whether it did
+func (q *queue[T]) tryPush(v T) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.max > 0 && len(q.items) >= q.max {
+		return false
+	}
+	q.items = append(q.items, v)
+	q.cond.Broadcast()
+	return true
+}
+
+// pop removes the oldest item, blocking while the queue is empty.
+// It returns false once the queue is closed and fully drained.
+func (q *queue[T]) pop() (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if len(q.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	v := q.items[0]
+	var zero T
+	q.items[0] = zero
+	q.items = q.items[1:]
+	q.cond.Broadcast()
+	return v, true
+}
+
+// close marks the end of input; pending items can still be popped
+func (q *queue[T]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// len returns the number of items currently waiting in the queue
+func (q *queue[T]) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// limit returns the current maximum number of items
+func (q *queue[T]) limit() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.max
+}
+
+// setLimit changes the maximum number of items, waking pushes blocked on the old limit
+func (q *queue[T]) setLimit(max int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.max = max
+	q.cond.Broadcast()
+}
+
+// droppedCount returns how many items the overflow policy has discarded
+func (q *queue[T]) droppedCount() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.dropped
+}
@@ -0,0 +1,149 @@
+package functools
+
+// Result carries either a value or the error produced while computing it
+type Result[T any] struct {
+	Value T
+	Err   error
+}
+
+// Dematerialize unwraps a stream of results into a stream of values, stopping at the
+// first error and halting the source. The returned function reports that error (nil if
+// the source ended cleanly) and should be called once the stream has been fully consumed.
+func Dematerialize[T any](s *streamable[Result[T]]) (*streamable[T], func() error) {
+	out, done, result := stage[Result[T], T](s)
+	var failure error
+	go func() {
+		defer close(out)
+		for r := range s.stream {
+			if r.Err != nil {
+				failure = r.Err
+				s.halt()
+				return
+			}
+			select {
+			case out <- r.Value:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result, func() error { return failure }
+}
+
+// RetryIf unwraps a stream of results like Dematerialize, but when an error matches
+// shouldRetry it halts the current stream and continues from a fresh one returned by
+// source, up to attempts times. Errors that aren't retryable, or that remain once the
+// attempts are exhausted, end the stream and are reported by the returned function,
+// which should be called once the stream has been fully consumed.
+func RetryIf[T any](s *streamable[Result[T]], shouldRetry func(error) bool, attempts int, source func() *streamable[Result[T]]) (*streamable[T], func() error) {
+	out := make(chan T)
+	var failure error
+	go func() {
+		defer close(out)
+		current := s
+		retries := 0
+		for {
+			var err error
+			for r := range current.stream {
+				if r.Err != nil {
+					err = r.Err
+					break
+				}
+				out <- r.Value
+			}
+			if err == nil {
+				return
+			}
+			current.halt()
+			if !shouldRetry(err) || retries >= attempts {
+				failure = err
+				return
+			}
+			retries++
+			current = source()
+		}
+	}()
+	return &streamable[T]{stream: out}, func() error { return failure }
+}
+
+// SplitResults lazily routes successful values to one stream and errors to another as
+// they arrive. Both streams close when the source closes, and both must be consumed,
+// since a blocked output stalls the other.
+func SplitResults[T any](s *streamable[Result[T]]) (*streamable[T], *streamable[error]) {
+	values := make(chan T)
+	errs := make(chan error)
+	go func() {
+		defer close(values)
+		defer close(errs)
+		for r := range s.stream {
+			if r.Err != nil {
+				errs <- r.Err
+				continue
+			}
+			values <- r.Value
+		}
+	}()
+	return &streamable[T]{stream: values}, &streamable[error]{stream: errs}
+}
+
+// PipeWithDLQ applies fn to each item, sending successful results to results and the
+// original item with its error to deadLetters. Both streams close when the source closes,
+// and both must be consumed, since a blocked output stalls the other.
+func PipeWithDLQ[T, R any](s *streamable[T], fn func(T) (R, error)) (results *streamable[R], deadLetters *streamable[struct {
+	Item T
+	Err  error
+}]) {
+	out := make(chan R)
+	dlq := make(chan struct {
+		Item T
+		Err  error
+	})
+	go func() {
+		defer close(out)
+		defer close(dlq)
+		for v := range s.stream {
+			r, err := fn(v)
+			if err != nil {
+				dlq <- struct {
+					Item T
+					Err  error
+				}{Item: v, Err: err}
+				continue
+			}
+			out <- r
+		}
+	}()
+	return &streamable[R]{stream: out}, &streamable[struct {
+		Item T
+		Err  error
+	}]{stream: dlq}
+}
+
+// ConcatMapErr expands every item into a slice of outputs and emits each of them as a
+// successful Result, or emits a single error Result when fn fails for that item.
+// Outputs keep the source order, and an error doesn't stop the following items.
+func ConcatMapErr[T, R any](s *streamable[T], fn func(T) ([]R, error)) *streamable[Result[R]] {
+	out, done, result := stage[T, Result[R]](s)
+	go func() {
+		defer close(out)
+		for v := range s.stream {
+			rs, err := fn(v)
+			if err != nil {
+				select {
+				case out <- Result[R]{Err: err}:
+				case <-done:
+					return
+				}
+				continue
+			}
+			for _, r := range rs {
+				select {
+				case out <- Result[R]{Value: r}:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
@@ -1,6 +1,15 @@
 package functools
 
-import "sort"
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+)
 
 // iterable holds a generic slice with two type parameters
 type iterable[InputType any] struct {
@@ -12,6 +21,22 @@ func Slicefy[InputType any](items []InputType) *iterable[InputType] {
 	return &iterable[InputType]{items: items}
 }
 
+// Unfold builds an iterable from a seed by repeatedly calling fn, which returns the
+// next value, the next state and whether to continue. It stops as soon as fn returns
+// false, so fn must eventually do so or Unfold never returns.
+func Unfold[S, T any](seed S, fn func(S) (T, S, bool)) *iterable[T] {
+	var result []T
+	state := seed
+	for {
+		v, next, ok := fn(state)
+		if !ok {
+			return &iterable[T]{items: result}
+		}
+		result = append(result, v)
+		state = next
+	}
+}
+
 // Filter returns a new iterable with only the items that pass the filter (without changing the type)
 func (c *iterable[InputType]) Filter(fn func(InputType) bool) *iterable[InputType] {
 	var result []InputType
@@ -23,6 +48,30 @@ func (c *iterable[InputType]) Filter(fn func(InputType) bool) *iterable[InputTyp
 	return &iterable[InputType]{items: result}
 }
 
+// FilterNot returns a new iterable with only the items for which fn returns false
+func (c *iterable[InputType]) FilterNot(fn func(InputType) bool) *iterable[InputType] {
+	return c.Filter(func(v InputType) bool { return !fn(v) })
+}
+
+// RejectBy is an alias of FilterNot
+func (c *iterable[InputType]) RejectBy(fn func(InputType) bool) *iterable[InputType] {
+	return c.FilterNot(fn)
+}
+
+// Partition splits the iterable in a single pass into the items for which fn returns
+// true and those for which it returns false, preserving their order.
+func (c *iterable[InputType]) Partition(fn func(InputType) bool) (*iterable[InputType], *iterable[InputType]) {
+	pass, fail := []InputType{}, []InputType{}
+	for _, v := range c.items {
+		if fn(v) {
+			pass = append(pass, v)
+		} else {
+			fail = append(fail, v)
+		}
+	}
+	return &iterable[InputType]{items: pass}, &iterable[InputType]{items: fail}
+}
+
 // ForEach executes the function fn on each item (no return)
 func (c *iterable[InputType]) ForEach(fn func(InputType)) {
 	for _, v := range c.items {
@@ -30,6 +79,37 @@ func (c *iterable[InputType]) ForEach(fn func(InputType)) {
 	}
 }
 
+// ForEachUntil executes fn on each item, stopping as soon as fn returns false
+func (c *iterable[InputType]) ForEachUntil(fn func(InputType) bool) {
+	for _, v := range c.items {
+		if !fn(v) {
+			return
+		}
+	}
+}
+
+// ProcessBatches calls fn on consecutive batches of size items (the last batch may be
+// smaller) and, after each successful batch, reports the number of items processed so
+// far and the total to onProgress, which may be nil. It stops at the first error.
+// Batches are views of the underlying items, capped so appending to one can't overwrite
+// the next.
+func (c *iterable[InputType]) ProcessBatches(size int, fn func(batch []InputType) error, onProgress func(done, total int)) error {
+	if size < 1 {
+		size = 1
+	}
+	total := len(c.items)
+	for start := 0; start < total; start += size {
+		end := min(start+size, total)
+		if err := fn(c.items[start:end:end]); err != nil {
+			return err
+		}
+		if onProgress != nil {
+			onProgress(end, total)
+		}
+	}
+	return nil
+}
+
 // Map applies the transformation function fn and returns a new iterable
 func (c *iterable[InputType]) Map(fn func(InputType) any) *iterable[any] {
 	var result []any
@@ -39,11 +119,65 @@ func (c *iterable[InputType]) Map(fn func(InputType) any) *iterable[any] {
 	return &iterable[any]{items: result}
 }
 
+// ApplyParallel applies fn to every item across workers goroutines and returns the
+// results in the original order, keeping the item type. Non-positive workers default to NumCPU.
+func (c *iterable[InputType]) ApplyParallel(workers int, fn func(InputType) InputType) *iterable[InputType] {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	result := make([]InputType, len(c.items))
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result[i] = fn(c.items[i])
+			}
+		}()
+	}
+	for i := range c.items {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	return &iterable[InputType]{items: result}
+}
+
+// ApplyIf returns a new iterable where fn is applied to the items satisfying pred,
+// leaving the other items unchanged.
+func (c *iterable[InputType]) ApplyIf(pred func(InputType) bool, fn func(InputType) InputType) *iterable[InputType] {
+	result := make([]InputType, len(c.items))
+	for i, v := range c.items {
+		if pred(v) {
+			v = fn(v)
+		}
+		result[i] = v
+	}
+	return &iterable[InputType]{items: result}
+}
+
 // ToSlice returns the internal slice (for anyone to access directly)
 func (c *iterable[InputType]) ToSlice() []InputType {
 	return c.items
 }
 
+// Clone returns a new iterable with a shallow copy of the items
+func (c *iterable[InputType]) Clone() *iterable[InputType] {
+	return &iterable[InputType]{items: append([]InputType{}, c.items...)}
+}
+
+// DeepClone returns a fully independent iterable by applying copyFn to every item.
+// Use it when items hold slices, maps or pointers that must not be shared.
+func (c *iterable[InputType]) DeepClone(copyFn func(InputType) InputType) *iterable[InputType] {
+	result := make([]InputType, len(c.items))
+	for i, v := range c.items {
+		result[i] = copyFn(v)
+	}
+	return &iterable[InputType]{items: result}
+}
+
 // Reduce reduces the iterable to a single value based on the provided function.
 func (c *iterable[InputType]) Reduce(fn func(acc InputType, item InputType) InputType, initial InputType) InputType {
 	acc := initial
@@ -53,6 +187,20 @@ func (c *iterable[InputType]) Reduce(fn func(acc InputType, item InputType) Inpu
 	return acc
 }
 
+// Reduce1 reduces the iterable using the first element as the initial accumulator.
+// It returns false when the iterable is empty, so no dummy initial value is needed.
+func (c *iterable[InputType]) Reduce1(fn func(acc InputType, item InputType) InputType) (InputType, bool) {
+	if len(c.items) == 0 {
+		var zero InputType
+		return zero, false
+	}
+	acc := c.items[0]
+	for _, v := range c.items[1:] {
+		acc = fn(acc, v)
+	}
+	return acc, true
+}
+
 // Find returns the first element that satisfies the condition or nil.
 func (c *iterable[InputType]) Find(fn func(InputType) bool) *InputType {
 	for _, v := range c.items {
@@ -63,6 +211,26 @@ func (c *iterable[InputType]) Find(fn func(InputType) bool) *InputType {
 	return nil
 }
 
+// FindLast returns the last element that satisfies the condition, scanning from the end.
+// The boolean is false when no element matches.
+func (c *iterable[InputType]) FindLast(fn func(InputType) bool) (InputType, bool) {
+	if i := c.FindLastIndex(fn); i >= 0 {
+		return c.items[i], true
+	}
+	var zero InputType
+	return zero, false
+}
+
+// FindLastIndex returns the index of the last element that satisfies the condition, or -1.
+func (c *iterable[InputType]) FindLastIndex(fn func(InputType) bool) int {
+	for i := len(c.items) - 1; i >= 0; i-- {
+		if fn(c.items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
 // Some checks if at least one element satisfies the condition.
 func (c *iterable[InputType]) Some(fn func(InputType) bool) bool {
 	for _, v := range c.items {
@@ -92,6 +260,24 @@ func (c *iterable[InputType]) Sort(fn func(a, b InputType) bool) *iterable[Input
 	return &iterable[InputType]{items: sorted}
 }
 
+// SortInPlace sorts the elements in place using a comparison function and returns the receiver.
+// Unlike Sort, it mutates the original backing slice instead of copying it.
+func (c *iterable[InputType]) SortInPlace(fn func(a, b InputType) bool) *iterable[InputType] {
+	sort.SliceStable(c.items, func(i, j int) bool {
+		return fn(c.items[i], c.items[j])
+	})
+	return c
+}
+
+// ReverseInPlace reverses the elements in place and returns the receiver.
+// It mutates the original backing slice instead of copying it.
+func (c *iterable[InputType]) ReverseInPlace() *iterable[InputType] {
+	for i, j := 0, len(c.items)-1; i < j; i, j = i+1, j-1 {
+		c.items[i], c.items[j] = c.items[j], c.items[i]
+	}
+	return c
+}
+
 // Concat concatenates the current iterable with another iterable and returns a new iterable.
 func (c *iterable[InputType]) Concat(other []InputType) *iterable[InputType] {
 	// Combine the two slices
@@ -99,6 +285,28 @@ func (c *iterable[InputType]) Concat(other []InputType) *iterable[InputType] {
 	return &iterable[InputType]{items: combinedItems}
 }
 
+// PadRight returns a copy of the iterable extended to length items by appending fill.
+// Iterables that already have at least length items are copied unchanged.
+func (c *iterable[InputType]) PadRight(length int, fill InputType) *iterable[InputType] {
+	padded := make([]InputType, 0, max(length, len(c.items)))
+	padded = append(padded, c.items...)
+	for len(padded) < length {
+		padded = append(padded, fill)
+	}
+	return &iterable[InputType]{items: padded}
+}
+
+// PadLeft returns a copy of the iterable extended to length items by prepending fill.
+// Iterables that already have at least length items are copied unchanged.
+func (c *iterable[InputType]) PadLeft(length int, fill InputType) *iterable[InputType] {
+	padded := make([]InputType, 0, max(length, len(c.items)))
+	for i := len(c.items); i < length; i++ {
+		padded = append(padded, fill)
+	}
+	padded = append(padded, c.items...)
+	return &iterable[InputType]{items: padded}
+}
+
 // Slice extracts a subset of the iterable (like slicing an array).
 func (c *iterable[InputType]) Slice(start, end int) *iterable[InputType] {
 	if start < 0 || end > len(c.items) || start > end {
@@ -107,6 +315,64 @@ func (c *iterable[InputType]) Slice(start, end int) *iterable[InputType] {
 	return &iterable[InputType]{items: c.items[start:end]}
 }
 
+// TakeLast returns a copy of the last n items, or of every item when n exceeds the length.
+// A negative n is treated as 0.
+func (c *iterable[InputType]) TakeLast(n int) *iterable[InputType] {
+	n = clamp(n, 0, len(c.items))
+	return &iterable[InputType]{items: append([]InputType{}, c.items[len(c.items)-n:]...)}
+}
+
+// DropLast returns a copy of every item except the last n, which is empty when n exceeds
+// the length. A negative n is treated as 0.
+func (c *iterable[InputType]) DropLast(n int) *iterable[InputType] {
+	n = clamp(n, 0, len(c.items))
+	return &iterable[InputType]{items: append([]InputType{}, c.items[:len(c.items)-n]...)}
+}
+
+// OrElse returns the iterable itself when it has items, otherwise an iterable wrapping defaults.
+func (c *iterable[InputType]) OrElse(defaults []InputType) *iterable[InputType] {
+	if len(c.items) > 0 {
+		return c
+	}
+	return &iterable[InputType]{items: defaults}
+}
+
+// OrElseGet works like OrElse but only computes the defaults when the iterable is empty.
+func (c *iterable[InputType]) OrElseGet(fn func() []InputType) *iterable[InputType] {
+	if len(c.items) > 0 {
+		return c
+	}
+	return &iterable[InputType]{items: fn()}
+}
+
+// Swap returns a new iterable with the items at i and j exchanged.
+// If either index is out of range, an unchanged copy is returned.
+func (c *iterable[InputType]) Swap(i, j int) *iterable[InputType] {
+	result := append([]InputType{}, c.items...)
+	if i < 0 || j < 0 || i >= len(result) || j >= len(result) {
+		return &iterable[InputType]{items: result}
+	}
+	result[i], result[j] = result[j], result[i]
+	return &iterable[InputType]{items: result}
+}
+
+// Move returns a new iterable with the item at from relocated to index to,
+// shifting the items in between. If either index is out of range, an unchanged copy is returned.
+func (c *iterable[InputType]) Move(from, to int) *iterable[InputType] {
+	result := append([]InputType{}, c.items...)
+	if from < 0 || to < 0 || from >= len(result) || to >= len(result) {
+		return &iterable[InputType]{items: result}
+	}
+	item := result[from]
+	if from < to {
+		copy(result[from:to], result[from+1:to+1])
+	} else {
+		copy(result[to+1:from+1], result[to:from])
+	}
+	result[to] = item
+	return &iterable[InputType]{items: result}
+}
+
 // ToStream converts an iterable to a streamable
 func (c *iterable[InputType]) ToStream() *streamable[InputType] {
 	ch := make(chan InputType)
@@ -142,3 +408,792 @@ func RecastSlice[SliceType any](input *iterable[any]) *iterable[SliceType] {
 	}
 	return &iterable[SliceType]{items: result}
 }
+
+// Combinations returns every k-element combination of the iterable, in index order.
+// The result grows combinatorially (n choose k), so keep inputs small.
+func Combinations[T any](c *iterable[T], k int) *iterable[[]T] {
+	n := len(c.items)
+	result := [][]T{}
+	if k < 0 || k > n {
+		return &iterable[[]T]{items: result}
+	}
+	indices := make([]int, k)
+	for i := range indices {
+		indices[i] = i
+	}
+	for {
+		combo := make([]T, k)
+		for i, idx := range indices {
+			combo[i] = c.items[idx]
+		}
+		result = append(result, combo)
+
+		// Advance the rightmost index that still has room to move
+		i := k - 1
+		for i >= 0 && indices[i] == n-k+i {
+			i--
+		}
+		if i < 0 {
+			return &iterable[[]T]{items: result}
+		}
+		indices[i]++
+		for j := i + 1; j < k; j++ {
+			indices[j] = indices[j-1] + 1
+		}
+	}
+}
+
+// Permutations returns every ordering of the iterable, in lexicographic index order.
+// The result grows factorially (n!), so keep inputs small.
+func Permutations[T any](c *iterable[T]) *iterable[[]T] {
+	n := len(c.items)
+	result := [][]T{}
+	current := make([]T, 0, n)
+	used := make([]bool, n)
+	var permute func()
+	permute = func() {
+		if len(current) == n {
+			result = append(result, append([]T{}, current...))
+			return
+		}
+		for i, v := range c.items {
+			if used[i] {
+				continue
+			}
+			used[i] = true
+			current = append(current, v)
+			permute()
+			current = current[:len(current)-1]
+			used[i] = false
+		}
+	}
+	permute()
+	return &iterable[[]T]{items: result}
+}
+
+// GroupBySorted groups the items by key and also returns the keys in ascending order,
+// so the groups can be iterated deterministically.
+func GroupBySorted[T any, K cmp.Ordered](c *iterable[T], key func(T) K) (map[K]*iterable[T], []K) {
+	groups := make(map[K]*iterable[T])
+	var keys []K
+	for _, v := range c.items {
+		k := key(v)
+		group, ok := groups[k]
+		if !ok {
+			group = &iterable[T]{}
+			groups[k] = group
+			keys = append(keys, k)
+		}
+		group.items = append(group.items, v)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return cmp.Less(keys[i], keys[j])
+	})
+	return groups, keys
+}
+
+// ScanFold folds the iterable like Reduce, returning both the running accumulator
+// after each element and the final accumulator in a single pass.
+// The intermediate iterable excludes the initial value, so it has one entry per element.
+func ScanFold[T, Acc any](c *iterable[T], fn func(acc Acc, item T) Acc, initial Acc) (*iterable[Acc], Acc) {
+	steps := make([]Acc, 0, len(c.items))
+	acc := initial
+	for _, v := range c.items {
+		acc = fn(acc, v)
+		steps = append(steps, acc)
+	}
+	return &iterable[Acc]{items: steps}, acc
+}
+
+// FlattenIterables concatenates several iterables into a new one, allocating once
+// for the total length. Nil iterables are skipped and the inputs are left untouched.
+func FlattenIterables[T any](iters ...*iterable[T]) *iterable[T] {
+	total := 0
+	for _, it := range iters {
+		if it != nil {
+			total += len(it.items)
+		}
+	}
+	result := make([]T, 0, total)
+	for _, it := range iters {
+		if it != nil {
+			result = append(result, it.items...)
+		}
+	}
+	return &iterable[T]{items: result}
+}
+
+// ZipToMap builds a map by pairing keys with values positionally, up to the shorter
+// of the two iterables. When a key repeats, the last value wins.
+func ZipToMap[K comparable, V any](keys *iterable[K], values *iterable[V]) map[K]V {
+	n := min(len(keys.items), len(values.items))
+	result := make(map[K]V, n)
+	for i := 0; i < n; i++ {
+		result[keys.items[i]] = values.items[i]
+	}
+	return result
+}
+
+// MapCollectErrors applies fn to every element without stopping at failures.
+// Successful results are collected in order, and each error is wrapped with the
+// index of the element that produced it.
+func MapCollectErrors[T, R any](c *iterable[T], fn func(T) (R, error)) (*iterable[R], []error) {
+	var results []R
+	var errs []error
+	for i, v := range c.items {
+		r, err := fn(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("item %d: %w", i, err))
+			continue
+		}
+		results = append(results, r)
+	}
+	return &iterable[R]{items: results}, errs
+}
+
+// Number is satisfied by every built-in integer and floating-point type
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// Summary holds the count, sum, extremes and average of a set of numbers
+type Summary[T Number] struct {
+	Count         int
+	Sum, Min, Max T
+	Avg           float64
+}
+
+// Aggregate computes the count, sum, min, max and average of the iterable in a single pass.
+// An empty iterable yields the zero Summary, with Count 0 and Avg 0.
+func Aggregate[T Number](c *iterable[T]) Summary[T] {
+	var summary Summary[T]
+	for i, v := range c.items {
+		if i == 0 || v < summary.Min {
+			summary.Min = v
+		}
+		if i == 0 || v > summary.Max {
+			summary.Max = v
+		}
+		summary.Sum += v
+	}
+	summary.Count = len(c.items)
+	if summary.Count > 0 {
+		summary.Avg = float64(summary.Sum) / float64(summary.Count)
+	}
+	return summary
+}
+
+// ChunkStream lazily emits the iterable in chunks of size items (the last chunk may be
+// smaller), so each batch is only formed when the consumer is ready for it.
+// Chunks are independent copies of the underlying items.
+func ChunkStream[T any](c *iterable[T], size int) *streamable[[]T] {
+	if size < 1 {
+		size = 1
+	}
+	out := make(chan []T)
+	go func() {
+		defer close(out)
+		for start := 0; start < len(c.items); start += size {
+			end := min(start+size, len(c.items))
+			out <- append([]T{}, c.items[start:end]...)
+		}
+	}()
+	return &streamable[[]T]{stream: out}
+}
+
+// Expand folds the iterable into a growing result slice: fn receives the outputs
+// accumulated so far and returns them with the item's contribution added, which may be
+// any number of new outputs. Unlike a flat map, each step can inspect earlier outputs.
+func Expand[T, R any](c *iterable[T], fn func(state []R, item T) []R, initial []R) *iterable[R] {
+	state := append([]R{}, initial...)
+	for _, v := range c.items {
+		state = fn(state, v)
+	}
+	return &iterable[R]{items: state}
+}
+
+// Scan folds the iterable like Reduce but returns the running accumulator after each
+// element. The initial value is not included, so there is one entry per element.
+func Scan[T, Acc any](c *iterable[T], fn func(acc Acc, item T) Acc, initial Acc) *iterable[Acc] {
+	return ScanIndexed(c, func(acc Acc, _ int, item T) Acc { return fn(acc, item) }, initial)
+}
+
+// ScanIndexed works like Scan but also passes the element index to fn.
+// The initial value is not included, so there is one entry per element.
+func ScanIndexed[T, Acc any](c *iterable[T], fn func(acc Acc, index int, item T) Acc, initial Acc) *iterable[Acc] {
+	steps := make([]Acc, 0, len(c.items))
+	acc := initial
+	for i, v := range c.items {
+		acc = fn(acc, i, v)
+		steps = append(steps, acc)
+	}
+	return &iterable[Acc]{items: steps}
+}
+
+// IsMonotonicIncreasing reports whether every item is >= its predecessor.
+// Empty and single-item iterables are trivially monotonic.
+func IsMonotonicIncreasing[T cmp.Ordered](c *iterable[T]) bool {
+	return isMonotonic(c, func(prev, next T) bool { return next >= prev })
+}
+
+// IsMonotonicDecreasing reports whether every item is <= its predecessor.
+// Empty and single-item iterables are trivially monotonic.
+func IsMonotonicDecreasing[T cmp.Ordered](c *iterable[T]) bool {
+	return isMonotonic(c, func(prev, next T) bool { return next <= prev })
+}
+
+// IsStrictlyIncreasing reports whether every item is > its predecessor.
+// Empty and single-item iterables are trivially monotonic.
+func IsStrictlyIncreasing[T cmp.Ordered](c *iterable[T]) bool {
+	return isMonotonic(c, func(prev, next T) bool { return next > prev })
+}
+
+// IsStrictlyDecreasing reports whether every item is < its predecessor.
+// Empty and single-item iterables are trivially monotonic.
+func IsStrictlyDecreasing[T cmp.Ordered](c *iterable[T]) bool {
+	return isMonotonic(c, func(prev, next T) bool { return next < prev })
+}
+
+// isMonotonic reports whether ok holds for every pair of consecutive items
+func isMonotonic[T any](c *iterable[T], ok func(prev, next T) bool) bool {
+	for i := 1; i < len(c.items); i++ {
+		if !ok(c.items[i-1], c.items[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// PartitionDuplicates separates the first occurrence of each item from its repeats.
+// unique holds first occurrences in order, and duplicates holds every later repeat in order.
+func PartitionDuplicates[T comparable](c *iterable[T]) (unique *iterable[T], duplicates *iterable[T]) {
+	seen := make(map[T]struct{}, len(c.items))
+	var first, repeats []T
+	for _, v := range c.items {
+		if _, ok := seen[v]; ok {
+			repeats = append(repeats, v)
+			continue
+		}
+		seen[v] = struct{}{}
+		first = append(first, v)
+	}
+	return &iterable[T]{items: first}, &iterable[T]{items: repeats}
+}
+
+// FoldMap maps every item with mapFn and combines the results with combine, starting
+// from identity. combine should be associative with identity as its neutral element,
+// so the result doesn't depend on how the items are grouped.
+func FoldMap[T, M any](c *iterable[T], mapFn func(T) M, combine func(a, b M) M, identity M) M {
+	acc := identity
+	for _, v := range c.items {
+		acc = combine(acc, mapFn(v))
+	}
+	return acc
+}
+
+// CartesianProduct returns every (a, b) pair, with a varying slowest (outer loop).
+// The result holds len(a)*len(b) pairs; use CartesianProductStream to avoid allocating them all.
+func CartesianProduct[A, B any](a *iterable[A], b *iterable[B]) *iterable[struct {
+	First  A
+	Second B
+}] {
+	result := make([]struct {
+		First  A
+		Second B
+	}, 0, len(a.items)*len(b.items))
+	for _, x := range a.items {
+		for _, y := range b.items {
+			result = append(result, struct {
+				First  A
+				Second B
+			}{First: x, Second: y})
+		}
+	}
+	return &iterable[struct {
+		First  A
+		Second B
+	}]{items: result}
+}
+
+// CartesianProductStream lazily emits every (a, b) pair in the same order as CartesianProduct
+func CartesianProductStream[A, B any](a *iterable[A], b *iterable[B]) *streamable[struct {
+	First  A
+	Second B
+}] {
+	out := make(chan struct {
+		First  A
+		Second B
+	})
+	go func() {
+		defer close(out)
+		for _, x := range a.items {
+			for _, y := range b.items {
+				out <- struct {
+					First  A
+					Second B
+				}{First: x, Second: y}
+			}
+		}
+	}()
+	return &streamable[struct {
+		First  A
+		Second B
+	}]{stream: out}
+}
+
+// BinarySearch looks for target in an iterable that must already be sorted consistently
+// with cmp (negative when a < b, zero when equal, positive when a > b). It returns the
+// index of target, or the index where it would be inserted, and whether it was found.
+func BinarySearch[T any](c *iterable[T], target T, cmp func(a, b T) int) (index int, found bool) {
+	index = sort.Search(len(c.items), func(i int) bool {
+		return cmp(c.items[i], target) >= 0
+	})
+	return index, index < len(c.items) && cmp(c.items[index], target) == 0
+}
+
+// BinarySearchOrdered is BinarySearch for naturally ordered types sorted in ascending order
+func BinarySearchOrdered[T cmp.Ordered](c *iterable[T], target T) (index int, found bool) {
+	return BinarySearch(c, target, cmp.Compare[T])
+}
+
+// ChunkOverlap splits the iterable into chunks of size items where consecutive chunks
+// share overlap items (each chunk starts size-overlap items after the previous one).
+// The last chunk may be smaller and ends at the last item; with no overlap this is a
+// plain chunking. Invalid arguments (size < 1, overlap < 0 or overlap >= size) yield
+// an empty iterable. Chunks are independent copies of the underlying items.
+func ChunkOverlap[T any](c *iterable[T], size, overlap int) *iterable[[]T] {
+	result := [][]T{}
+	if size < 1 || overlap < 0 || overlap >= size {
+		return &iterable[[]T]{items: result}
+	}
+	step := size - overlap
+	for start := 0; start < len(c.items); start += step {
+		end := min(start+size, len(c.items))
+		result = append(result, append([]T{}, c.items[start:end]...))
+		if end == len(c.items) {
+			break
+		}
+	}
+	return &iterable[[]T]{items: result}
+}
+
+// GroupMap groups the items by key and applies transform to each group, keeping the
+// items of a group in their original order. Iteration order of the resulting map is not
+// guaranteed (see GroupBySorted for deterministic key order).
+func GroupMap[T any, K comparable, R any](c *iterable[T], key func(T) K, transform func(group []T) R) map[K]R {
+	groups := GroupBy(c, key)
+	result := make(map[K]R, len(groups))
+	for k, group := range groups {
+		result[k] = transform(group)
+	}
+	return result
+}
+
+// WeightedSample selects n distinct items without replacement, each with probability
+// proportional to weight, using rng as the source of randomness. Items with zero or
+// negative weight are never selected, so fewer than n items are returned when there
+// aren't enough positive-weight items. Items are returned in selection order.
+func WeightedSample[T any](c *iterable[T], weight func(T) float64, n int, rng *rand.Rand) *iterable[T] {
+	type candidate struct {
+		item T
+		key  float64
+	}
+	// Efraimidis-Spirakis: the n largest keys u^(1/w) form a weighted sample
+	var candidates []candidate
+	for _, v := range c.items {
+		w := weight(v)
+		if w <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{item: v, key: math.Pow(rng.Float64(), 1/w)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].key > candidates[j].key
+	})
+	n = max(0, min(n, len(candidates)))
+	result := make([]T, n)
+	for i := range result {
+		result[i] = candidates[i].item
+	}
+	return &iterable[T]{items: result}
+}
+
+// EachCons returns every run of n consecutive items, like Ruby's each_cons.
+// Inputs shorter than n (or a non-positive n) yield an empty iterable.
+// Runs are independent copies of the underlying items.
+func EachCons[T any](c *iterable[T], n int) *iterable[[]T] {
+	result := [][]T{}
+	if n < 1 {
+		return &iterable[[]T]{items: result}
+	}
+	for start := 0; start+n <= len(c.items); start++ {
+		result = append(result, append([]T{}, c.items[start:start+n]...))
+	}
+	return &iterable[[]T]{items: result}
+}
+
+// MovingSum returns the sum of every window of size consecutive items, one per full
+// window, in O(1) per window by subtracting the item leaving the window and adding the one
+// entering it. Inputs shorter than size (or a non-positive size) yield an empty iterable.
+// For floating-point items the running sum can accumulate rounding error on long inputs.
+func MovingSum[T Number](c *iterable[T], size int) *iterable[T] {
+	result := []T{}
+	if size < 1 || size > len(c.items) {
+		return &iterable[T]{items: result}
+	}
+	result = make([]T, 0, len(c.items)-size+1)
+	var sum T
+	for _, v := range c.items[:size] {
+		sum += v
+	}
+	result = append(result, sum)
+	for i := size; i < len(c.items); i++ {
+		sum += c.items[i] - c.items[i-size]
+		result = append(result, sum)
+	}
+	return &iterable[T]{items: result}
+}
+
+// MovingAverage returns the mean of every window of size consecutive items, computed
+// from MovingSum and following the same windowing rules.
+func MovingAverage[T Number](c *iterable[T], size int) *iterable[float64] {
+	sums := MovingSum(c, size).items
+	result := make([]float64, len(sums))
+	for i, sum := range sums {
+		result[i] = float64(sum) / float64(size)
+	}
+	return &iterable[float64]{items: result}
+}
+
+// NormalizeMinMax rescales the items linearly to [0, 1], mapping the smallest item to 0
+// and the largest to 1. When every item is equal there is no range to scale by, so all
+// items map to 0.
+func NormalizeMinMax[T Number](c *iterable[T]) *iterable[float64] {
+	result := make([]float64, len(c.items))
+	if len(c.items) == 0 {
+		return &iterable[float64]{items: result}
+	}
+	low, high := c.items[0], c.items[0]
+	for _, v := range c.items[1:] {
+		low, high = min(low, v), max(high, v)
+	}
+	if low == high {
+		return &iterable[float64]{items: result}
+	}
+	span := float64(high) - float64(low)
+	for i, v := range c.items {
+		result[i] = (float64(v) - float64(low)) / span
+	}
+	return &iterable[float64]{items: result}
+}
+
+// Clamp returns a new iterable with every item limited to the range [low, high].
+// If low is greater than high the bounds are swapped.
+func Clamp[T cmp.Ordered](c *iterable[T], low, high T) *iterable[T] {
+	if low > high {
+		low, high = high, low
+	}
+	result := make([]T, len(c.items))
+	for i, v := range c.items {
+		result[i] = min(max(v, low), high)
+	}
+	return &iterable[T]{items: result}
+}
+
+// RunningMinMax returns, for every position, the smallest and largest items seen up to
+// and including it, which traces the envelope of a series in a single pass.
+func RunningMinMax[T cmp.Ordered](c *iterable[T]) *iterable[struct{ Min, Max T }] {
+	result := make([]struct{ Min, Max T }, len(c.items))
+	for i, v := range c.items {
+		if i == 0 {
+			result[i] = struct{ Min, Max T }{Min: v, Max: v}
+			continue
+		}
+		prev := result[i-1]
+		result[i] = struct{ Min, Max T }{Min: min(prev.Min, v), Max: max(prev.Max, v)}
+	}
+	return &iterable[struct{ Min, Max T }]{items: result}
+}
+
+// Rank sorts the items by less (stably) and assigns 1-based ranks in that sorted order.
+// Items are tied when neither is less than the other; tied items share a rank and the
+// following rank skips ahead by the size of the tie (1, 2, 2, 4).
+func Rank[T any](c *iterable[T], less func(a, b T) bool) *iterable[struct {
+	Item T
+	Rank int
+}] {
+	return rank(c, less, false)
+}
+
+// DenseRank works like Rank, but ranks following a tie don't skip ahead (1, 2, 2, 3).
+func DenseRank[T any](c *iterable[T], less func(a, b T) bool) *iterable[struct {
+	Item T
+	Rank int
+}] {
+	return rank(c, less, true)
+}
+
+// rank assigns ranks over the sorted items, with or without gaps after ties
+func rank[T any](c *iterable[T], less func(a, b T) bool, dense bool) *iterable[struct {
+	Item T
+	Rank int
+}] {
+	sorted := c.Sort(less).items
+	result := make([]struct {
+		Item T
+		Rank int
+	}, len(sorted))
+	current := 0
+	for i, v := range sorted {
+		if i == 0 || less(sorted[i-1], v) {
+			if dense {
+				current++
+			} else {
+				current = i + 1
+			}
+		}
+		result[i].Item = v
+		result[i].Rank = current
+	}
+	return &iterable[struct {
+		Item T
+		Rank int
+	}]{items: result}
+}
+
+// TreeNode is a node of a tree built by BuildTree
+type TreeNode[T any] struct {
+	Item     T
+	Children []*TreeNode[T]
+}
+
+// BuildTree assembles a forest from flat records, linking each item to the item whose id
+// equals its parentID (parentID returns false for roots). Orphans, whose parent is not
+// present, are treated as roots. Roots and children keep the order of the input.
+// Records with duplicate ids are all kept, and children attach to the last of them.
+// Items whose parent chain loops back on itself would be unreachable, so each cycle is
+// broken at its earliest item in the input, which becomes an extra root after the others.
+func BuildTree[T any, K comparable](c *iterable[T], id func(T) K, parentID func(T) (K, bool)) []*TreeNode[T] {
+	nodes := make([]*TreeNode[T], len(c.items))
+	byID := make(map[K]*TreeNode[T], len(c.items))
+	for i, v := range c.items {
+		nodes[i] = &TreeNode[T]{Item: v}
+		byID[id(v)] = nodes[i]
+	}
+	parents := make(map[*TreeNode[T]]*TreeNode[T], len(nodes))
+	var roots []*TreeNode[T]
+	for _, node := range nodes {
+		if pid, ok := parentID(node.Item); ok {
+			if parent, found := byID[pid]; found && parent != node {
+				parent.Children = append(parent.Children, node)
+				parents[node] = parent
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	reached := make(map[*TreeNode[T]]bool, len(nodes))
+	var reach func(node *TreeNode[T])
+	reach = func(node *TreeNode[T]) {
+		reached[node] = true
+		for _, child := range node.Children {
+			reach(child)
+		}
+	}
+	for _, root := range roots {
+		reach(root)
+	}
+	order := make(map[*TreeNode[T]]int, len(nodes))
+	for i, node := range nodes {
+		order[node] = i
+	}
+	for _, node := range nodes {
+		if reached[node] {
+			continue
+		}
+		// Walking up from an unreached node always ends in a cycle
+		seen := make(map[*TreeNode[T]]bool)
+		for !seen[node] {
+			seen[node] = true
+			node = parents[node]
+		}
+		first := node
+		for member := parents[node]; member != node; member = parents[member] {
+			if order[member] < order[first] {
+				first = member
+			}
+		}
+		parent := parents[first]
+		parent.Children = slices.DeleteFunc(parent.Children, func(n *TreeNode[T]) bool { return n == first })
+		delete(parents, first)
+		roots = append(roots, first)
+		reach(first)
+	}
+	return roots
+}
+
+// SplitBy buckets the items by category into plain slices, preserving order within each
+// bucket. It is meant for small fixed sets of categories such as status codes or levels.
+func SplitBy[T any, K comparable](c *iterable[T], classify func(T) K) map[K][]T {
+	return GroupBy(c, classify)
+}
+
+// InterleaveIterables takes one item from each iterable in turn, skipping exhausted ones,
+// until all are consumed. The order within each iterable is preserved and nil iterables
+// are skipped.
+func InterleaveIterables[T any](iters ...*iterable[T]) *iterable[T] {
+	total, longest := 0, 0
+	for _, it := range iters {
+		if it != nil {
+			total += len(it.items)
+			longest = max(longest, len(it.items))
+		}
+	}
+	result := make([]T, 0, total)
+	for i := 0; i < longest; i++ {
+		for _, it := range iters {
+			if it != nil && i < len(it.items) {
+				result = append(result, it.items[i])
+			}
+		}
+	}
+	return &iterable[T]{items: result}
+}
+
+// GroupByTwo buckets the items by two keys and folds each innermost group with reduce,
+// starting each group from a fresh value returned by initial.
+func GroupByTwo[T any, K1, K2 comparable, V any](c *iterable[T], k1 func(T) K1, k2 func(T) K2, reduce func(acc V, item T) V, initial func() V) map[K1]map[K2]V {
+	result := make(map[K1]map[K2]V)
+	for _, v := range c.items {
+		outer, inner := k1(v), k2(v)
+		group, ok := result[outer]
+		if !ok {
+			group = make(map[K2]V)
+			result[outer] = group
+		}
+		acc, ok := group[inner]
+		if !ok {
+			acc = initial()
+		}
+		group[inner] = reduce(acc, v)
+	}
+	return result
+}
+
+// Map applies fn to every item and returns the results as a typed iterable, unlike the
+// Map method, which produces an iterable[any] that needs RecastSlice afterwards.
+func Map[T, R any](c *iterable[T], fn func(T) R) *iterable[R] {
+	result := make([]R, len(c.items))
+	for i, v := range c.items {
+		result[i] = fn(v)
+	}
+	return &iterable[R]{items: result}
+}
+
+// Fold reduces the iterable into an accumulator of a different type than the items,
+// such as a map or a summary struct, starting from initial.
+func Fold[T, A any](c *iterable[T], fn func(acc A, item T) A, initial A) A {
+	acc := initial
+	for _, v := range c.items {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// FlatMap maps every item to a slice of results and concatenates them in order.
+func FlatMap[T, R any](c *iterable[T], fn func(T) []R) *iterable[R] {
+	result := []R{}
+	for _, v := range c.items {
+		result = append(result, fn(v)...)
+	}
+	return &iterable[R]{items: result}
+}
+
+// Flatten concatenates the nested slices of an iterable, such as the output of
+// ChunkOverlap or EachCons, into a single iterable, preserving order.
+func Flatten[T any](c *iterable[[]T]) *iterable[T] {
+	total := 0
+	for _, inner := range c.items {
+		total += len(inner)
+	}
+	result := make([]T, 0, total)
+	for _, inner := range c.items {
+		result = append(result, inner...)
+	}
+	return &iterable[T]{items: result}
+}
+
+// Chunk splits the iterable into groups of size items (the last may be smaller), as copies
+// of the underlying items. A size below 1 is treated as 1.
+func Chunk[T any](c *iterable[T], size int) *iterable[[]T] {
+	if size < 1 {
+		size = 1
+	}
+	result := make([][]T, 0, (len(c.items)+size-1)/size)
+	for start := 0; start < len(c.items); start += size {
+		end := min(start+size, len(c.items))
+		result = append(result, append([]T{}, c.items[start:end]...))
+	}
+	return &iterable[[]T]{items: result}
+}
+
+// Pair holds two values of possibly different types, as produced by Zip
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Zip pairs the items of a and b by position, stopping at the end of the shorter one.
+func Zip[A, B any](a *iterable[A], b *iterable[B]) *iterable[Pair[A, B]] {
+	return ZipWith(a, b, func(x A, y B) Pair[A, B] { return Pair[A, B]{First: x, Second: y} })
+}
+
+// ZipWith combines the items of a and b by position with fn, stopping at the end of the
+// shorter one.
+func ZipWith[A, B, C any](a *iterable[A], b *iterable[B], fn func(A, B) C) *iterable[C] {
+	n := min(len(a.items), len(b.items))
+	result := make([]C, n)
+	for i := 0; i < n; i++ {
+		result[i] = fn(a.items[i], b.items[i])
+	}
+	return &iterable[C]{items: result}
+}
+
+// Unzip splits an iterable of pairs into an iterable of the first values and one of the
+// second values, the inverse of Zip.
+func Unzip[A, B any](c *iterable[Pair[A, B]]) (*iterable[A], *iterable[B]) {
+	firsts := make([]A, len(c.items))
+	seconds := make([]B, len(c.items))
+	for i, p := range c.items {
+		firsts[i], seconds[i] = p.First, p.Second
+	}
+	return &iterable[A]{items: firsts}, &iterable[B]{items: seconds}
+}
+
+// GroupBy buckets the items by key, keeping their original order within each group.
+func GroupBy[T any, K comparable](c *iterable[T], key func(T) K) map[K][]T {
+	groups := make(map[K][]T)
+	for _, v := range c.items {
+		k := key(v)
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// Distinct returns the items with duplicates removed, keeping the first occurrence of
+// each in its original position. Use PartitionDuplicates to also get the repeats.
+func Distinct[T comparable](c *iterable[T]) *iterable[T] {
+	seen := make(map[T]struct{}, len(c.items))
+	result := []T{}
+	for _, v := range c.items {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return &iterable[T]{items: result}
+}
@@ -0,0 +1,121 @@
+package functools
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+)
+
+// SpillingIterable holds a collected stream partly in memory and partly in a temp file.
+// Since the items may not fit in memory it doesn't offer the slice-backed iterable API:
+// it supports Len, ForEach and ToStream, and the stream operators (Filter, Pipe, ...)
+// can be applied to the result of ToStream.
+type SpillingIterable[T any] struct {
+	memory  []T
+	path    string
+	spilled int
+	err     error
+}
+
+// ToSpillingIterable collects a finite stream while keeping at most memLimit items in
+// memory, spilling the rest to a gob-encoded temp file, so datasets larger than RAM can
+// be collected and iterated again. Items must be gob-encodable (register interface types
+// with gob.Register). The returned function deletes the temp file once iteration is done.
+func (s *streamable[InputType]) ToSpillingIterable(memLimit int) (*SpillingIterable[InputType], func() error) {
+	result := &SpillingIterable[InputType]{}
+	var file *os.File
+	var writer *bufio.Writer
+	var encoder *gob.Encoder
+	for v := range s.stream {
+		if len(result.memory) < memLimit {
+			result.memory = append(result.memory, v)
+			continue
+		}
+		if result.err != nil {
+			continue
+		}
+		if encoder == nil {
+			file, result.err = os.CreateTemp("", "functools-spill-*.gob")
+			if result.err != nil {
+				continue
+			}
+			result.path = file.Name()
+			writer = bufio.NewWriter(file)
+			encoder = gob.NewEncoder(writer)
+		}
+		if result.err = encoder.Encode(&v); result.err == nil {
+			result.spilled++
+		}
+	}
+	if file != nil {
+		if err := writer.Flush(); err != nil && result.err == nil {
+			result.err = err
+		}
+		if err := file.Close(); err != nil && result.err == nil {
+			result.err = err
+		}
+	}
+	cleanup := func() error {
+		if result.path == "" {
+			return nil
+		}
+		err := os.Remove(result.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return result, cleanup
+}
+
+// Len returns the total number of collected items, in memory and on disk
+func (c *SpillingIterable[InputType]) Len() int {
+	return len(c.memory) + c.spilled
+}
+
+// ForEach executes fn on each item, first from memory and then reading back from disk
+// one item at a time. It returns any error from spilling or reading the temp file.
+func (c *SpillingIterable[InputType]) ForEach(fn func(InputType)) error {
+	if c.err != nil {
+		return c.err
+	}
+	for _, v := range c.memory {
+		fn(v)
+	}
+	if c.path == "" {
+		return nil
+	}
+	file, err := os.Open(c.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	decoder := gob.NewDecoder(bufio.NewReader(file))
+	for {
+		var v InputType
+		if err := decoder.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		fn(v)
+	}
+}
+
+// ToStream streams the items from memory and then from disk.
+// Reading stops at the first error, which is reported by the returned function
+// once the stream has been fully consumed.
+func (c *SpillingIterable[InputType]) ToStream() (*streamable[InputType], func() error) {
+	out := make(chan InputType)
+	var failure error
+	go func() {
+		defer close(out)
+		failure = c.ForEach(func(v InputType) {
+			out <- v
+		})
+	}()
+	return &streamable[InputType]{stream: out}, func() error { return failure }
+}
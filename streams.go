@@ -1,20 +1,67 @@
 package functools
 
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
 // streamable is a collection that processes data on-demand via channels
 type streamable[InputType any] struct {
 	stream <-chan InputType
+	// stop asks the producer to stop sending; nil when the producer can't be stopped
+	stop func()
+}
+
+// halt asks the producer of the stream to stop. Consumers that give up on a stream
+// before it closes call it instead of reading the rest. Sources created by Streamify and
+// CreateCancellableStream stop, as do the stages that forward the request upstream; other
+// producers can't be stopped, so the rest of their stream is drained in the background.
+func (s *streamable[InputType]) halt() {
+	if s.stop != nil {
+		s.stop()
+		return
+	}
+	go func() {
+		for range s.stream {
+		}
+	}()
+}
+
+// newStop returns a done channel and a function closing it, which is safe to call more than once
+func newStop() (<-chan struct{}, func()) {
+	done := make(chan struct{})
+	var once sync.Once
+	return done, func() { once.Do(func() { close(done) }) }
+}
+
+// stage creates the output of an operator reading from s. Halting the output closes
+// done, which the operator selects on while sending, and forwards the request upstream.
+func stage[T, R any](s *streamable[T]) (chan R, <-chan struct{}, *streamable[R]) {
+	out := make(chan R)
+	done, stop := newStop()
+	return out, done, &streamable[R]{stream: out, stop: func() {
+		stop()
+		s.halt()
+	}}
 }
 
 // Creates a streamable from a slice
 func Streamify[InputType any](items []InputType) *streamable[InputType] {
-	ch := make(chan InputType)
-	go func() {
-		defer close(ch)
+	return CreateCancellableStream(func(ch chan InputType, done <-chan struct{}) {
 		for _, v := range items {
-			ch <- v
+			select {
+			case ch <- v:
+			case <-done:
+				return
+			}
 		}
-	}()
-	return &streamable[InputType]{stream: ch}
+	})
 }
 
 // CreateStream creates a streamable by receiving a generator function
@@ -28,32 +75,249 @@ func CreateStream[InputType any](generator func(chan InputType)) *streamable[Inp
 	return &streamable[InputType]{stream: ch}
 }
 
+// CreateCancellableStream is like CreateStream, but the generator also receives a done
+// channel that is closed when a consumer stops reading early (for example FirstOrDefault
+// or WithDeadline). The generator should select on it while sending and return once it
+// is closed, which lets endless producers be stopped without leaking.
+func CreateCancellableStream[InputType any](generator func(ch chan InputType, done <-chan struct{})) *streamable[InputType] {
+	ch := make(chan InputType)
+	done, stop := newStop()
+	go func() {
+		defer close(ch)
+		generator(ch, done)
+	}()
+	return &streamable[InputType]{stream: ch, stop: stop}
+}
+
+// OverflowStrategy decides what a source does when its buffer is full
+type OverflowStrategy int
+
+const (
+	// OverflowBlock applies backpressure: the generator waits until the buffer has room.
+	OverflowBlock OverflowStrategy = iota
+	// OverflowDropOldest evicts the oldest buffered item to make room for the new one.
+	OverflowDropOldest
+	// OverflowDropNewest discards the incoming item.
+	OverflowDropNewest
+)
+
+// CreateStreamWithOverflow creates a streamable from a generator that shouldn't be slowed
+// down. Items go through an internal buffer of bufferSize items, and when the consumer
+// falls behind the overflow strategy either blocks the generator, drops the oldest
+// buffered item or drops the new one. The returned function reports how many items have
+// been dropped so far.
+func CreateStreamWithOverflow[InputType any](generator func(chan InputType), bufferSize int, overflow OverflowStrategy) (*streamable[InputType], func() int) {
+	if bufferSize < 1 {
+		bufferSize = 1
+	}
+	policy := SlowBlock
+	switch overflow {
+	case OverflowDropOldest:
+		policy = SlowDropOldest
+	case OverflowDropNewest:
+		policy = SlowDropNewest
+	}
+	q := newOverflowQueue[InputType](bufferSize, policy)
+	ch := make(chan InputType)
+	go func() {
+		defer close(ch)
+		generator(ch)
+	}()
+	go func() {
+		defer q.close()
+		for v := range ch {
+			q.push(v)
+		}
+	}()
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := q.pop()
+			if !ok {
+				return
+			}
+			out <- v
+		}
+	}()
+	return &streamable[InputType]{stream: out}, q.droppedCount
+}
+
 // Pipe creates a new streamable by applying fn to each item
 func (s *streamable[InputType]) Pipe(fn func(InputType) any) *streamable[any] {
-	out := make(chan any)
+	out, done, result := stage[InputType, any](s)
 	go func() {
 		defer close(out)
 		for v := range s.stream {
-			out <- fn(v)
+			select {
+			case out <- fn(v):
+			case <-done:
+				return
+			}
 		}
 	}()
-	return &streamable[any]{stream: out}
+	return result
 }
 
 // Filter creates a new streamable by filtering items with fn
 func (s *streamable[InputType]) Filter(fn func(InputType) bool) *streamable[InputType] {
-	out := make(chan InputType)
+	out, done, result := stage[InputType, InputType](s)
 	go func() {
 		defer close(out)
+		for v := range s.stream {
+			if !fn(v) {
+				continue
+			}
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// ApplyIf creates a new streamable where fn is applied to the items satisfying pred,
+// leaving the other items unchanged
+func (s *streamable[InputType]) ApplyIf(pred func(InputType) bool, fn func(InputType) InputType) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		for v := range s.stream {
+			if pred(v) {
+				v = fn(v)
+			}
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// FilterNot creates a new streamable keeping only the items for which fn returns false
+func (s *streamable[InputType]) FilterNot(fn func(InputType) bool) *streamable[InputType] {
+	return s.Filter(func(v InputType) bool { return !fn(v) })
+}
+
+// Partition splits the stream into the items for which fn returns true and those for
+// which it returns false, routing each item as it arrives. Both streams close when the
+// source closes, and both must be consumed, since a blocked output stalls the other.
+func (s *streamable[InputType]) Partition(fn func(InputType) bool) (*streamable[InputType], *streamable[InputType]) {
+	pass := make(chan InputType)
+	fail := make(chan InputType)
+	go func() {
+		defer close(pass)
+		defer close(fail)
 		for v := range s.stream {
 			if fn(v) {
-				out <- v
+				pass <- v
+			} else {
+				fail <- v
 			}
 		}
 	}()
+	return &streamable[InputType]{stream: pass}, &streamable[InputType]{stream: fail}
+}
+
+// FilterPar is like Filter but evaluates fn on up to workers items concurrently, which
+// pays off when the predicate is I/O bound. Items are forwarded in their original order,
+// so a slow item holds back the ones behind it; use FilterParUnordered when order does
+// not matter. A workers value below 1 defaults to runtime.NumCPU().
+func (s *streamable[InputType]) FilterPar(workers int, fn func(InputType) bool) *streamable[InputType] {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	type job struct {
+		value InputType
+		keep  chan bool
+	}
+	jobs := make(chan job)
+	// pending holds jobs in arrival order and bounds how far ahead the workers can run
+	pending := make(chan job, workers)
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for v := range s.stream {
+			j := job{value: v, keep: make(chan bool, 1)}
+			pending <- j
+			jobs <- j
+		}
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for j := range jobs {
+				j.keep <- fn(j.value)
+			}
+		}()
+	}
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		for j := range pending {
+			if <-j.keep {
+				out <- j.value
+			}
+		}
+	}()
+	return &streamable[InputType]{stream: out}
+}
+
+// FilterParUnordered is like FilterPar but forwards each passing item as soon as its
+// predicate completes, so the output order is not guaranteed.
+func (s *streamable[InputType]) FilterParUnordered(workers int, fn func(InputType) bool) *streamable[InputType] {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	out := make(chan InputType)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range s.stream {
+				if fn(v) {
+					out <- v
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
 	return &streamable[InputType]{stream: out}
 }
 
+// DefaultIfEmpty forwards every item unchanged, but emits def once if the stream closes
+// without having produced any item, so downstream stages never see an empty stream.
+func (s *streamable[InputType]) DefaultIfEmpty(def InputType) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		empty := true
+		for v := range s.stream {
+			empty = false
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+		if empty {
+			select {
+			case out <- def:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
 // ForEach consumes the stream by applying fn to each item
 func (s *streamable[InputType]) ForEach(fn func(InputType)) {
 	for v := range s.stream {
@@ -61,6 +325,29 @@ func (s *streamable[InputType]) ForEach(fn func(InputType)) {
 	}
 }
 
+// ForEachBatch consumes the stream in batches of size items, calling fn on each batch
+// and on the final partial batch. It stops at the first error returned by fn, leaving
+// the rest of the stream unread so processing can be resumed with another call.
+func (s *streamable[InputType]) ForEachBatch(size int, fn func(batch []InputType) error) error {
+	if size < 1 {
+		size = 1
+	}
+	batch := make([]InputType, 0, size)
+	for v := range s.stream {
+		batch = append(batch, v)
+		if len(batch) == size {
+			if err := fn(batch); err != nil {
+				return err
+			}
+			batch = make([]InputType, 0, size)
+		}
+	}
+	if len(batch) > 0 {
+		return fn(batch)
+	}
+	return nil
+}
+
 // ToSlice collects all items into a slice (may block until everything is consumed)
 func (s *streamable[InputType]) ToSlice() []InputType {
 	var result []InputType
@@ -70,6 +357,60 @@ func (s *streamable[InputType]) ToSlice() []InputType {
 	return result
 }
 
+// ToSliceWithin collects items until the stream closes or d elapses, whichever comes
+// first, and returns whatever was gathered. Unlike WithDeadline it is a terminal. At the
+// deadline it stops reading and halts the upstream, so a cancellable producer (see
+// CreateCancellableStream) stops instead of leaking.
+func (s *streamable[InputType]) ToSliceWithin(d time.Duration) []InputType {
+	result := []InputType{}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			s.halt()
+			return result
+		case v, ok := <-s.stream:
+			if !ok {
+				return result
+			}
+			result = append(result, v)
+		}
+	}
+}
+
+// FirstOrDefault returns the first item of the stream, or def if the stream is empty.
+// It reads nothing after the first item and halts the upstream, so a cancellable
+// producer (see CreateCancellableStream) stops instead of staying blocked.
+func (s *streamable[InputType]) FirstOrDefault(def InputType) InputType {
+	v, ok := <-s.stream
+	if !ok {
+		return def
+	}
+	s.halt()
+	return v
+}
+
+// LastOrDefault returns the last item of the stream, or def if the stream is empty.
+// It drains the whole stream, so the stream must be finite.
+func (s *streamable[InputType]) LastOrDefault(def InputType) InputType {
+	last := def
+	for v := range s.stream {
+		last = v
+	}
+	return last
+}
+
+// ToSortedSlice collects all items and returns them sorted by less, keeping equal items
+// in arrival order. The stream must be finite, since it is fully drained first.
+func (s *streamable[InputType]) ToSortedSlice(less func(a, b InputType) bool) []InputType {
+	result := s.ToSlice()
+	sort.SliceStable(result, func(i, j int) bool {
+		return less(result[i], result[j])
+	})
+	return result
+}
+
 func (s *streamable[InputType]) ToBufferedStream(bufferSize int) *bufferedStream[InputType] {
 	ch := make(chan InputType, bufferSize)
 	go func() {
@@ -81,16 +422,1269 @@ func (s *streamable[InputType]) ToBufferedStream(bufferSize int) *bufferedStream
 	return &bufferedStream[InputType]{stream: ch}
 }
 
+// AsyncBoundary decouples producer and consumer timing through an elastic buffer.
+// Items are read ahead into an internal buffer that grows up to maxBuffer items,
+// and backpressure is only applied upstream once the buffer is full.
+// The current buffer depth can be monitored with Depth on the returned stream.
+func (s *streamable[InputType]) AsyncBoundary(maxBuffer int) *bufferedStream[InputType] {
+	if maxBuffer < 1 {
+		maxBuffer = 1
+	}
+	q := newQueue[InputType](maxBuffer)
+	go func() {
+		defer q.close()
+		for v := range s.stream {
+			q.push(v)
+		}
+	}()
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := q.pop()
+			if !ok {
+				return
+			}
+			out <- v
+		}
+	}()
+	return &bufferedStream[InputType]{stream: out, BufferSize: maxBuffer, depth: q.len}
+}
+
+// AdaptiveBuffer is an AsyncBoundary whose buffer size adapts to the consumer.
+// It starts at min items, doubles (up to max) whenever the producer finds it full
+// because the consumer is lagging, and halves (down to min) whenever the consumer
+// drains it. The current size and depth are available through Capacity and Depth.
+func (s *streamable[InputType]) AdaptiveBuffer(min, max int) *bufferedStream[InputType] {
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+	q := newQueue[InputType](min)
+	go func() {
+		defer q.close()
+		for v := range s.stream {
+			if !q.tryPush(v) {
+				q.setLimit(clamp(q.limit()*2, min, max))
+				q.push(v)
+			}
+		}
+	}()
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := q.pop()
+			if !ok {
+				return
+			}
+			if q.len() == 0 {
+				q.setLimit(clamp(q.limit()/2, min, max))
+			}
+			out <- v
+		}
+	}()
+	return &bufferedStream[InputType]{stream: out, BufferSize: max, depth: q.len, capacity: q.limit}
+}
+
+// clamp limits v to the range [low, high]
+func clamp(v, low, high int) int {
+	return max(low, min(v, high))
+}
+
 func RecastStream[StreamType any](s *streamable[any]) *streamable[StreamType] {
-	out := make(chan StreamType)
+	out, done, result := stage[any, StreamType](s)
 	go func() {
 		defer close(out)
 		for v := range s.stream {
 			// Attempt to cast each item in the stream to OutputType
 			if casted, ok := v.(StreamType); ok {
-				out <- casted
+				select {
+				case out <- casted:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Route demultiplexes a stream into one output stream per key in keys.
+// Each item is forwarded to the stream of its classified key, and items whose key
+// is not in keys are dropped. All outputs close when the source closes.
+// Every returned stream must be consumed, since a blocked output stalls the others.
+func Route[T any, K comparable](s *streamable[T], classify func(T) K, keys []K) map[K]*streamable[T] {
+	outs := make(map[K]chan T, len(keys))
+	result := make(map[K]*streamable[T], len(keys))
+	for _, k := range keys {
+		if _, ok := outs[k]; ok {
+			continue
+		}
+		ch := make(chan T)
+		outs[k] = ch
+		result[k] = &streamable[T]{stream: ch}
+	}
+	go func() {
+		defer func() {
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+		for v := range s.stream {
+			if ch, ok := outs[classify(v)]; ok {
+				ch <- v
+			}
+		}
+	}()
+	return result
+}
+
+// Gate forwards items while the latest value received on control is true and
+// pauses (applying backpressure, holding at most one item read ahead) while it is false.
+// If the source closes during a pause with no item held, the output closes right away.
+// The gate starts open, and closing control leaves it open for the rest of the stream.
+func (s *streamable[InputType]) Gate(control <-chan bool) *streamable[InputType] {
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		open := true
+		// update applies a control message, treating a closed control as open
+		update := func(value, ok bool) {
+			if !ok {
+				control = nil
+				open = true
+				return
+			}
+			open = value
+		}
+		var pending InputType
+		hasPending := false
+		for {
+			if !hasPending {
+				// Keep watching the source even while paused, so its close is noticed
+				select {
+				case item, ok := <-s.stream:
+					if !ok {
+						return
+					}
+					pending, hasPending = item, true
+				case value, ok := <-control:
+					update(value, ok)
+				}
+				continue
+			}
+			if !open {
+				value, ok := <-control
+				update(value, ok)
+				continue
+			}
+			select {
+			case out <- pending:
+				hasPending = false
+			case value, ok := <-control:
+				update(value, ok)
+			}
+		}
+	}()
+	return &streamable[InputType]{stream: out}
+}
+
+// Conflate folds items arriving while the consumer is busy into a single pending
+// value using combine, and emits it as soon as the consumer is ready.
+// With combine returning next, only the latest item is kept.
+func (s *streamable[InputType]) Conflate(combine func(acc, next InputType) InputType) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		var pending InputType
+		hasPending := false
+		in := s.stream
+		for in != nil || hasPending {
+			// A nil channel disables the send case until there is something pending
+			var send chan InputType
+			if hasPending {
+				send = out
+			}
+			select {
+			case v, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+				if hasPending {
+					pending = combine(pending, v)
+				} else {
+					pending = v
+					hasPending = true
+				}
+			case send <- pending:
+				hasPending = false
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// ExpandRecursive creates a streamable that emits seeds and everything reachable
+// from them through expand, in breadth-first order. Each item is expanded only after
+// it has been emitted. Closing done stops the traversal, as does a consumer that stops
+// reading early (for example FirstOrDefault or WithDeadline); one of them is required to
+// bound infinite or cyclic expansions (use ExpandRecursiveDistinct to skip revisits).
+func ExpandRecursive[T any](seeds []T, expand func(T) []T, done <-chan struct{}) *streamable[T] {
+	return expandRecursive(seeds, expand, func(T) bool { return true }, done)
+}
+
+// ExpandRecursiveDistinct works like ExpandRecursive but tracks visited items by key,
+// so each key is emitted and expanded at most once, even on cyclic graphs.
+func ExpandRecursiveDistinct[T any, K comparable](seeds []T, expand func(T) []T, key func(T) K, done <-chan struct{}) *streamable[T] {
+	visited := make(map[K]struct{})
+	return expandRecursive(seeds, expand, func(v T) bool {
+		k := key(v)
+		if _, ok := visited[k]; ok {
+			return false
+		}
+		visited[k] = struct{}{}
+		return true
+	}, done)
+}
+
+// expandRecursive runs the breadth-first traversal, enqueueing only items accepted by visit.
+// It stops when done is closed or when the returned stream is halted.
+func expandRecursive[T any](seeds []T, expand func(T) []T, visit func(T) bool, done <-chan struct{}) *streamable[T] {
+	return CreateCancellableStream(func(ch chan T, halted <-chan struct{}) {
+		var pending []T
+		for _, v := range seeds {
+			if visit(v) {
+				pending = append(pending, v)
+			}
+		}
+		for len(pending) > 0 {
+			v := pending[0]
+			pending = pending[1:]
+			select {
+			case <-done:
+				return
+			case <-halted:
+				return
+			default:
+			}
+			select {
+			case ch <- v:
+			case <-done:
+				return
+			case <-halted:
+				return
+			}
+			for _, next := range expand(v) {
+				if visit(next) {
+					pending = append(pending, next)
+				}
+			}
+		}
+	})
+}
+
+// WithDeadline forwards items until d has elapsed since the call, then closes the
+// output regardless of whether more items remain. At the deadline it stops reading and
+// halts the upstream, so a cancellable producer (see CreateCancellableStream) returns
+// instead of leaking.
+func (s *streamable[InputType]) WithDeadline(d time.Duration) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	timer := time.NewTimer(d)
+	go func() {
+		defer close(out)
+		defer timer.Stop()
+		for {
+			select {
+			case <-timer.C:
+				s.halt()
+				return
+			case <-done:
+				return
+			case v, ok := <-s.stream:
+				if !ok {
+					return
+				}
+				select {
+				case out <- v:
+				case <-timer.C:
+					s.halt()
+					return
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// ApproxFrequency drains the stream into a Count-Min Sketch and returns a function
+// estimating how many times an item was seen. Estimates never undercount; they may
+// overcount by about e/width * total items with probability 1 - e^-depth, so a larger
+// width lowers the error and a larger depth raises the confidence, at width*depth counters.
+func ApproxFrequency[T any](s *streamable[T], hash func(T) uint64, width, depth int) func(T) uint64 {
+	if width < 1 {
+		width = 1
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	counters := make([][]uint64, depth)
+	for i := range counters {
+		counters[i] = make([]uint64, width)
+	}
+	// cell derives an independent column per row from the single user hash
+	cell := func(h uint64, row int) int {
+		return int(mix64(h^uint64(row)*0x9e3779b97f4a7c15) % uint64(width))
+	}
+	for v := range s.stream {
+		h := hash(v)
+		for row := range counters {
+			counters[row][cell(h, row)]++
+		}
+	}
+	return func(item T) uint64 {
+		h := hash(item)
+		var estimate uint64
+		for row := range counters {
+			if count := counters[row][cell(h, row)]; row == 0 || count < estimate {
+				estimate = count
+			}
+		}
+		return estimate
+	}
+}
+
+// mix64 scrambles the bits of a hash (SplitMix64 finalizer)
+func mix64(h uint64) uint64 {
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
+
+// Progress forwards items and calls report with the running count every `every` items,
+// plus a final report with the total when the stream closes unless that total was just
+// reported (an empty stream reports 0). Reports run on a separate goroutine in order,
+// so a slow report never blocks the stream.
+func (s *streamable[InputType]) Progress(every int, report func(count int)) *streamable[InputType] {
+	if every < 1 {
+		every = 1
+	}
+	out, done, result := stage[InputType, InputType](s)
+	reports := newQueue[int](0)
+	go func() {
+		for {
+			count, ok := reports.pop()
+			if !ok {
+				return
+			}
+			report(count)
+		}
+	}()
+	go func() {
+		defer close(out)
+		defer reports.close()
+		count := 0
+		for v := range s.stream {
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+			count++
+			if count%every == 0 {
+				reports.push(count)
+			}
+		}
+		// The total was already reported when it is a multiple of every
+		if count == 0 || count%every != 0 {
+			reports.push(count)
+		}
+	}()
+	return result
+}
+
+// DistinctBloom forwards items whose hash has (probably) not been seen before,
+// tracking them in a Bloom filter sized for expectedItems at falsePositiveRate.
+// Memory stays fixed regardless of stream length, but false positives mean a few
+// unique items may be dropped; lower the rate to trade memory for accuracy.
+func DistinctBloom[T any](s *streamable[T], hash func(T) uint64, expectedItems int, falsePositiveRate float64) *streamable[T] {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.01
+	}
+	n := float64(expectedItems)
+	bits := uint64(math.Ceil(-n * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	hashes := max(1, int(math.Round(float64(bits)/n*math.Ln2)))
+	filter := make([]uint64, (bits+63)/64)
+
+	out, done, result := stage[T, T](s)
+	go func() {
+		defer close(out)
+		for v := range s.stream {
+			// Double hashing derives all probe positions from two base hashes
+			h1 := mix64(hash(v))
+			h2 := mix64(h1) | 1
+			seen := true
+			for i := 0; i < hashes; i++ {
+				bit := (h1 + uint64(i)*h2) % bits
+				word, mask := bit/64, uint64(1)<<(bit%64)
+				if filter[word]&mask == 0 {
+					seen = false
+					filter[word] |= mask
+				}
+			}
+			if !seen {
+				select {
+				case out <- v:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// Elapsed annotates each item with the time since the previous item arrived
+// (zero for the first one), which helps spot where a pipeline stalls.
+func Elapsed[T any](s *streamable[T]) *streamable[struct {
+	Value T
+	Since time.Duration
+}] {
+	out, done, result := stage[T, struct {
+		Value T
+		Since time.Duration
+	}](s)
+	go func() {
+		defer close(out)
+		var last time.Time
+		for v := range s.stream {
+			now := time.Now()
+			var since time.Duration
+			if !last.IsZero() {
+				since = now.Sub(last)
+			}
+			last = now
+			select {
+			case out <- struct {
+				Value T
+				Since time.Duration
+			}{Value: v, Since: since}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// MergeByTimestamp merges individually time-ordered streams into one stream ordered
+// by ts. Items are held in a buffer until the watermark (the latest timestamp seen
+// on any stream minus lateness) passes them, so arrivals up to lateness out of
+// order are still emitted in order. Items later than that are emitted as soon as
+// they arrive. Whatever remains buffered is flushed in order once all streams close.
+func MergeByTimestamp[T any](ts func(T) time.Time, lateness time.Duration, streams ...*streamable[T]) *streamable[T] {
+	in := make(chan T)
+	var wg sync.WaitGroup
+	for _, s := range streams {
+		wg.Add(1)
+		go func(s *streamable[T]) {
+			defer wg.Done()
+			for v := range s.stream {
+				in <- v
+			}
+		}(s)
+	}
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		pending := &timestampHeap[T]{}
+		var latest time.Time
+		seq := 0
+		for v := range in {
+			t := ts(v)
+			if t.After(latest) {
+				latest = t
+			}
+			heap.Push(pending, timestamped[T]{value: v, at: t, seq: seq})
+			seq++
+			watermark := latest.Add(-lateness)
+			for pending.Len() > 0 && !(*pending)[0].at.After(watermark) {
+				out <- heap.Pop(pending).(timestamped[T]).value
+			}
+		}
+		for pending.Len() > 0 {
+			out <- heap.Pop(pending).(timestamped[T]).value
+		}
+	}()
+	return &streamable[T]{stream: out}
+}
+
+// timestamped is an item buffered by MergeByTimestamp, with seq keeping ties in arrival order
+type timestamped[T any] struct {
+	value T
+	at    time.Time
+	seq   int
+}
+
+// timestampHeap is a min-heap of timestamped items implementing heap.Interface
+type timestampHeap[T any] []timestamped[T]
+
+func (h timestampHeap[T]) Len() int { return len(h) }
+func (h timestampHeap[T]) Less(i, j int) bool {
+	if h[i].at.Equal(h[j].at) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].at.Before(h[j].at)
+}
+func (h timestampHeap[T]) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *timestampHeap[T]) Push(x any)   { *h = append(*h, x.(timestamped[T])) }
+func (h *timestampHeap[T]) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// ShardByHash splits a stream into n streams, sending each item to shard hash(item) % n
+// so equal keys always land on the same shard. All shards close when the source closes,
+// and every shard must be consumed, since a blocked shard stalls the others.
+func ShardByHash[T any](s *streamable[T], n int, hash func(T) uint64) []*streamable[T] {
+	if n < 1 {
+		n = 1
+	}
+	outs := make([]chan T, n)
+	shards := make([]*streamable[T], n)
+	for i := range outs {
+		outs[i] = make(chan T)
+		shards[i] = &streamable[T]{stream: outs[i]}
+	}
+	go func() {
+		defer func() {
+			for _, ch := range outs {
+				close(ch)
+			}
+		}()
+		for v := range s.stream {
+			outs[hash(v)%uint64(n)] <- v
+		}
+	}()
+	return shards
+}
+
+// SampleOnInterval emits, every d, the latest item received since the previous tick,
+// skipping ticks where nothing new arrived. Like RxJS sample, an item still pending
+// when the source closes is not emitted.
+func (s *streamable[InputType]) SampleOnInterval(d time.Duration) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		ticker := time.NewTicker(d)
+		defer ticker.Stop()
+		var latest InputType
+		hasNew := false
+		for {
+			select {
+			case v, ok := <-s.stream:
+				if !ok {
+					return
+				}
+				latest = v
+				hasNew = true
+			case <-ticker.C:
+				if hasNew {
+					select {
+					case out <- latest:
+					case <-done:
+						return
+					}
+					hasNew = false
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// WithLatestFrom emits one item per primary item, paired with the most recent value
+// seen on other. Primary items arriving before other has produced anything are dropped.
+// The output closes with primary, and other is then halted rather than read further.
+// Halting the output halts both streams.
+func WithLatestFrom[A, B any](primary *streamable[A], other *streamable[B]) *streamable[struct {
+	Primary A
+	Latest  B
+}] {
+	out, done, result := stage[A, struct {
+		Primary A
+		Latest  B
+	}](primary)
+	go func() {
+		defer close(out)
+		secondary := other.stream
+		// other is halted once, whether primary closes or the output is halted
+		defer func() {
+			if secondary != nil {
+				other.halt()
+			}
+		}()
+		var latest B
+		hasLatest := false
+		for {
+			select {
+			case v, ok := <-secondary:
+				if !ok {
+					secondary = nil
+					continue
+				}
+				latest = v
+				hasLatest = true
+			case v, ok := <-primary.stream:
+				if !ok {
+					return
+				}
+				if hasLatest {
+					select {
+					case out <- struct {
+						Primary A
+						Latest  B
+					}{Primary: v, Latest: latest}:
+					case <-done:
+						return
+					}
+				}
+			case <-done:
+				return
 			}
 		}
 	}()
-	return &streamable[StreamType]{stream: out}
+	return result
+}
+
+// Histogram drains a numeric stream and counts items into the ranges defined by the
+// bucket boundaries. Boundaries [0, 10, 100] produce the labels "(-Inf,0)", "[0,10)",
+// "[10,100)" and "[100,+Inf)", and every label is present even when its count is 0.
+func Histogram[T Number](s *streamable[T], buckets []T) map[string]int {
+	bounds := append([]T{}, buckets...)
+	sort.Slice(bounds, func(i, j int) bool { return bounds[i] < bounds[j] })
+
+	labels := make([]string, len(bounds)+1)
+	counts := make(map[string]int, len(labels))
+	for i := range labels {
+		switch {
+		case len(bounds) == 0:
+			labels[i] = "(-Inf,+Inf)"
+		case i == 0:
+			labels[i] = fmt.Sprintf("(-Inf,%v)", bounds[0])
+		case i == len(bounds):
+			labels[i] = fmt.Sprintf("[%v,+Inf)", bounds[i-1])
+		default:
+			labels[i] = fmt.Sprintf("[%v,%v)", bounds[i-1], bounds[i])
+		}
+		counts[labels[i]] = 0
+	}
+	for v := range s.stream {
+		// Index of the first boundary greater than v is the bucket holding v
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > v })
+		counts[labels[i]]++
+	}
+	return counts
+}
+
+// Pace smooths bursts into a steady output of at most one item every rate.
+// Bursts are absorbed by an internal buffer of up to maxBuffer items, beyond which
+// backpressure is applied upstream. When the buffer runs dry, Pace waits for the
+// next item and emits it as soon as a full rate has passed since the previous one.
+func (s *streamable[InputType]) Pace(rate time.Duration, maxBuffer int) *streamable[InputType] {
+	if maxBuffer < 1 {
+		maxBuffer = 1
+	}
+	q := newQueue[InputType](maxBuffer)
+	go func() {
+		defer q.close()
+		for v := range s.stream {
+			q.push(v)
+		}
+	}()
+	out := make(chan InputType)
+	go func() {
+		defer close(out)
+		var last time.Time
+		for {
+			v, ok := q.pop()
+			if !ok {
+				return
+			}
+			if !last.IsZero() {
+				time.Sleep(time.Until(last.Add(rate)))
+			}
+			out <- v
+			last = time.Now()
+		}
+	}()
+	return &streamable[InputType]{stream: out}
+}
+
+// TokenBucket forwards items while tokens are available in a bucket holding up to burst
+// tokens and refilled at rate tokens per second. Each item consumes one token, so bursts
+// of up to burst items pass immediately and the sustained throughput is capped at rate.
+// When the bucket is empty it waits for the next token, applying backpressure instead of dropping.
+// It panics if rate is not positive, since the bucket would never refill.
+func (s *streamable[InputType]) TokenBucket(rate float64, burst int) *streamable[InputType] {
+	if !(rate > 0) {
+		panic(fmt.Sprintf("functools: TokenBucket rate must be positive, got %v", rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		tokens := float64(burst)
+		last := time.Now()
+		for v := range s.stream {
+			now := time.Now()
+			tokens = math.Min(float64(burst), tokens+now.Sub(last).Seconds()*rate)
+			last = now
+			if tokens < 1 {
+				wait := time.Duration((1 - tokens) / rate * float64(time.Second))
+				time.Sleep(wait)
+				now = time.Now()
+				tokens = math.Min(float64(burst), tokens+now.Sub(last).Seconds()*rate)
+				last = now
+			}
+			tokens--
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// DropLate splits a stream by event time into on-time and late items. An item is late
+// when its timestamp is more than allowedLateness behind the latest timestamp seen so far.
+// Both streams close when the source closes, and both must be consumed, since a blocked
+// output stalls the other.
+func DropLate[T any](s *streamable[T], ts func(T) time.Time, allowedLateness time.Duration) (*streamable[T], *streamable[T]) {
+	onTime := make(chan T)
+	late := make(chan T)
+	go func() {
+		defer close(onTime)
+		defer close(late)
+		var watermark time.Time
+		for v := range s.stream {
+			t := ts(v)
+			if !watermark.IsZero() && t.Before(watermark.Add(-allowedLateness)) {
+				late <- v
+				continue
+			}
+			if t.After(watermark) {
+				watermark = t
+			}
+			onTime <- v
+		}
+	}()
+	return &streamable[T]{stream: onTime}, &streamable[T]{stream: late}
+}
+
+// WindowStats keeps a sliding window over the last size items and, once the window is
+// full, emits a Summary of it for every new item. The sum is updated incrementally and
+// min/max are tracked with monotonic queues, so each item costs O(1) amortized.
+func WindowStats[T Number](s *streamable[T], size int) *streamable[Summary[T]] {
+	if size < 1 {
+		size = 1
+	}
+	out, done, result := stage[T, Summary[T]](s)
+	go func() {
+		defer close(out)
+		window := make([]T, size)
+		var sum T
+		// Indices of candidate extremes, oldest first
+		var mins, maxs []int
+		for i := 0; ; i++ {
+			v, ok := <-s.stream
+			if !ok {
+				return
+			}
+			if i >= size {
+				sum -= window[i%size]
+			}
+			window[i%size] = v
+			sum += v
+
+			oldest := i - size + 1
+			for len(mins) > 0 && window[mins[len(mins)-1]%size] >= v {
+				mins = mins[:len(mins)-1]
+			}
+			mins = append(mins, i)
+			if mins[0] < oldest {
+				mins = mins[1:]
+			}
+			for len(maxs) > 0 && window[maxs[len(maxs)-1]%size] <= v {
+				maxs = maxs[:len(maxs)-1]
+			}
+			maxs = append(maxs, i)
+			if maxs[0] < oldest {
+				maxs = maxs[1:]
+			}
+
+			if i >= size-1 {
+				summary := Summary[T]{
+					Count: size,
+					Sum:   sum,
+					Min:   window[mins[0]%size],
+					Max:   window[maxs[0]%size],
+					Avg:   float64(sum) / float64(size),
+				}
+				select {
+				case out <- summary:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// ChangesBy forwards an item only when its key differs from the key of the previously
+// forwarded item, emitting the full item on each state transition. The first item is
+// always forwarded.
+func ChangesBy[T any, K comparable](s *streamable[T], key func(T) K) *streamable[T] {
+	out, done, result := stage[T, T](s)
+	go func() {
+		defer close(out)
+		var last K
+		first := true
+		for v := range s.stream {
+			k := key(v)
+			if !first && k == last {
+				continue
+			}
+			first = false
+			last = k
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// JoinByKey performs a windowed inner join of two streams. Each item is buffered for
+// window after it arrives, and whenever an item arrives whose key matches buffered items
+// from the other stream, one pair is emitted per match. Every matching pair whose items
+// arrive within window of each other is emitted exactly once, while items that find no
+// match before expiring are dropped, which bounds memory to the items of one window.
+// The output closes once both streams close.
+func JoinByKey[A, B any, K comparable](a *streamable[A], keyA func(A) K, b *streamable[B], keyB func(B) K, window time.Duration) *streamable[struct {
+	A A
+	B B
+}] {
+	out := make(chan struct {
+		A A
+		B B
+	})
+	go func() {
+		defer close(out)
+		bufferA := newJoinBuffer[K, A]()
+		bufferB := newJoinBuffer[K, B]()
+		inA, inB := a.stream, b.stream
+		for inA != nil || inB != nil {
+			select {
+			case v, ok := <-inA:
+				if !ok {
+					inA = nil
+					continue
+				}
+				now := time.Now()
+				bufferA.evictBefore(now.Add(-window))
+				bufferB.evictBefore(now.Add(-window))
+				k := keyA(v)
+				for _, match := range bufferB.byKey[k] {
+					out <- struct {
+						A A
+						B B
+					}{A: v, B: match.value}
+				}
+				bufferA.add(k, v, now)
+			case v, ok := <-inB:
+				if !ok {
+					inB = nil
+					continue
+				}
+				now := time.Now()
+				bufferA.evictBefore(now.Add(-window))
+				bufferB.evictBefore(now.Add(-window))
+				k := keyB(v)
+				for _, match := range bufferA.byKey[k] {
+					out <- struct {
+						A A
+						B B
+					}{A: match.value, B: v}
+				}
+				bufferB.add(k, v, now)
+			}
+		}
+	}()
+	return &streamable[struct {
+		A A
+		B B
+	}]{stream: out}
+}
+
+// arrival is an item buffered together with the time it arrived
+type arrival[T any] struct {
+	value T
+	at    time.Time
+}
+
+// joinBuffer holds one side of a join grouped by key, remembering the overall arrival
+// order so expired items can be evicted oldest first without scanning every key
+type joinBuffer[K comparable, T any] struct {
+	byKey map[K][]arrival[T]
+	order []arrival[K]
+}
+
+func newJoinBuffer[K comparable, T any]() *joinBuffer[K, T] {
+	return &joinBuffer[K, T]{byKey: make(map[K][]arrival[T])}
+}
+
+// add buffers v under k; arrivals must be added in time order
+func (b *joinBuffer[K, T]) add(k K, v T, at time.Time) {
+	b.byKey[k] = append(b.byKey[k], arrival[T]{value: v, at: at})
+	b.order = append(b.order, arrival[K]{value: k, at: at})
+}
+
+// evictBefore drops the buffered arrivals older than cutoff, removing emptied keys.
+// Each item is evicted once, so the cost is amortized O(1) per item.
+func (b *joinBuffer[K, T]) evictBefore(cutoff time.Time) {
+	for len(b.order) > 0 && b.order[0].at.Before(cutoff) {
+		k := b.order[0].value
+		b.order = b.order[1:]
+		if entries := b.byKey[k][1:]; len(entries) > 0 {
+			b.byKey[k] = entries
+		} else {
+			delete(b.byKey, k)
+		}
+	}
+}
+
+// Checkpoint annotates each item with its running Offset (the number of items processed
+// so far, including itself) and sets Checkpoint on every `every`-th item, so a sink can
+// durably record progress inline with the data and resume from the last checkpoint.
+func Checkpoint[T any](s *streamable[T], every int) *streamable[struct {
+	Item       T
+	Checkpoint bool
+	Offset     int
+}] {
+	if every < 1 {
+		every = 1
+	}
+	out, done, result := stage[T, struct {
+		Item       T
+		Checkpoint bool
+		Offset     int
+	}](s)
+	go func() {
+		defer close(out)
+		offset := 0
+		for v := range s.stream {
+			offset++
+			select {
+			case out <- struct {
+				Item       T
+				Checkpoint bool
+				Offset     int
+			}{Item: v, Checkpoint: offset%every == 0, Offset: offset}:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// AuditTime emits the latest item at the end of a d-long window that starts when an item
+// arrives while no window is open, like RxJS auditTime. Items during the window only
+// replace the pending value, and the next item after it opens a new window, so a busy
+// stream is sampled at its trailing edges every d. This differs from a throttle, which
+// emits the leading item of each window, and from a debounce, which waits for d of
+// silence and never emits while the stream stays busy. A pending item is flushed when
+// the source closes.
+func (s *streamable[InputType]) AuditTime(d time.Duration) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		var latest InputType
+		var window <-chan time.Time
+		for {
+			select {
+			case v, ok := <-s.stream:
+				if !ok {
+					if window != nil {
+						select {
+						case out <- latest:
+						case <-done:
+						}
+					}
+					return
+				}
+				latest = v
+				if window == nil {
+					window = time.After(d)
+				}
+			case <-window:
+				window = nil
+				select {
+				case out <- latest:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// Track returns a pass-through stream together with a goroutine-safe getter reporting
+// the last item forwarded and whether any item has been forwarded yet, so another
+// goroutine can poll the current value without consuming the stream.
+func (s *streamable[InputType]) Track() (*streamable[InputType], func() (InputType, bool)) {
+	var latest atomic.Pointer[InputType]
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		for v := range s.stream {
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+			latest.Store(&v)
+		}
+	}()
+	get := func() (InputType, bool) {
+		if v := latest.Load(); v != nil {
+			return *v, true
+		}
+		var zero InputType
+		return zero, false
+	}
+	return result, get
+}
+
+// EveryNth forwards the items at positions 0, n, 2n, ... and drops the rest
+func (s *streamable[InputType]) EveryNth(n int) *streamable[InputType] {
+	if n < 1 {
+		n = 1
+	}
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		i := 0
+		for v := range s.stream {
+			if i%n == 0 {
+				select {
+				case out <- v:
+				case <-done:
+					return
+				}
+			}
+			i++
+		}
+	}()
+	return result
+}
+
+// AtIndices forwards only the items at the given positions. Once the largest requested
+// index has been passed it reads nothing more and halts the upstream, so a cancellable
+// producer (see CreateCancellableStream) stops instead of being read to the end.
+func (s *streamable[InputType]) AtIndices(indices ...int) *streamable[InputType] {
+	wanted := append([]int{}, indices...)
+	sort.Ints(wanted)
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		// Skip negative indices, which can never match
+		next := sort.SearchInts(wanted, 0)
+		i := 0
+		for next < len(wanted) {
+			v, ok := <-s.stream
+			if !ok {
+				return
+			}
+			if i == wanted[next] {
+				select {
+				case out <- v:
+				case <-done:
+					return
+				}
+				// Skip repeated indices
+				for next < len(wanted) && wanted[next] == i {
+					next++
+				}
+			}
+			i++
+		}
+		s.halt()
+	}()
+	return result
+}
+
+// Instrument forwards items while reporting them to metrics callbacks: onItem is called
+// for every item and onClose with the total count when the stream closes. Either callback
+// may be nil. Callbacks run inline, so they should be cheap. name is ignored: it is kept
+// only to match the requested signature, so bind the callbacks to the stage's metrics.
+func (s *streamable[InputType]) Instrument(name string, onItem func(), onClose func(total int)) *streamable[InputType] {
+	out, done, result := stage[InputType, InputType](s)
+	go func() {
+		defer close(out)
+		total := 0
+		for v := range s.stream {
+			total++
+			if onItem != nil {
+				onItem()
+			}
+			select {
+			case out <- v:
+			case <-done:
+				return
+			}
+		}
+		if onClose != nil {
+			onClose(total)
+		}
+	}()
+	return result
+}
+
+// GroupWithin collects items into groups that are emitted as soon as maxCount items have
+// been collected or maxIdle passes without a new item, whichever comes first, and flushes
+// any partial group when the source closes. The idle timer restarts on every item, which
+// makes this suitable for sessionizing activity. A maxCount below 1 disables the count
+// limit. It is a function rather than a method because the element type changes to []T.
+func GroupWithin[T any](s *streamable[T], maxCount int, maxIdle time.Duration) *streamable[[]T] {
+	out, done, result := stage[T, []T](s)
+	go func() {
+		defer close(out)
+		var group []T
+		var idle <-chan time.Time
+		// flush reports false once the output has been halted
+		flush := func() bool {
+			select {
+			case out <- group:
+			case <-done:
+				return false
+			}
+			group = nil
+			idle = nil
+			return true
+		}
+		for {
+			select {
+			case v, ok := <-s.stream:
+				if !ok {
+					if len(group) > 0 {
+						flush()
+					}
+					return
+				}
+				group = append(group, v)
+				if maxCount > 0 && len(group) >= maxCount {
+					if !flush() {
+						return
+					}
+					continue
+				}
+				idle = time.After(maxIdle)
+			case <-idle:
+				if !flush() {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
+}
+
+// FlatMapConcat maps every item to an inner stream and concatenates the inner streams:
+// each one is read to completion before fn is called for the next item, so the outputs
+// of different items never interleave.
+func FlatMapConcat[T, R any](s *streamable[T], fn func(T) *streamable[R]) *streamable[R] {
+	out, done, result := stage[T, R](s)
+	go func() {
+		defer close(out)
+		for v := range s.stream {
+			inner := fn(v)
+			for {
+				var r R
+				var ok bool
+				select {
+				case r, ok = <-inner.stream:
+				case <-done:
+					inner.halt()
+					return
+				}
+				if !ok {
+					break
+				}
+				select {
+				case out <- r:
+				case <-done:
+					inner.halt()
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+// MapAccumulate transforms each item while threading a state value through the stream,
+// like Haskell's mapAccumL: fn receives the current state and the item and returns the
+// next state together with the output to emit.
+func MapAccumulate[T, S, R any](s *streamable[T], initial S, fn func(state S, item T) (S, R)) *streamable[R] {
+	out, done, result := stage[T, R](s)
+	go func() {
+		defer close(out)
+		state := initial
+		for v := range s.stream {
+			var r R
+			state, r = fn(state, v)
+			select {
+			case out <- r:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return result
 }
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	functools "github.com/felipegenef/functools"
+)
+
+func TestMulticastBlock(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	broadcaster := functools.Multicast(functools.Streamify(items), functools.SlowBlock)
+
+	fast := broadcaster.Subscribe(1)
+	slow := broadcaster.Subscribe(1)
+	broadcaster.Start()
+
+	var wg sync.WaitGroup
+	var fastResult, slowResult []int
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		fastResult = fast.ToSlice()
+	}()
+	go func() {
+		defer wg.Done()
+		slow.ForEach(func(x int) {
+			time.Sleep(2 * time.Millisecond)
+			slowResult = append(slowResult, x)
+		})
+	}()
+	wg.Wait()
+
+	// Blocking delivers every item to every subscriber
+	if !reflect.DeepEqual(fastResult, items) {
+		t.Errorf("Expected %v, got %v", items, fastResult)
+	}
+	if !reflect.DeepEqual(slowResult, items) {
+		t.Errorf("Expected %v, got %v", items, slowResult)
+	}
+}
+
+func TestMulticastDropNewest(t *testing.T) {
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	broadcaster := functools.Multicast(stream, functools.SlowDropNewest)
+	slow := broadcaster.Subscribe(2)
+	broadcaster.Start()
+
+	// The subscriber doesn't read yet, so its buffer fills and later items are dropped
+	for i := 1; i <= 10; i++ {
+		source <- i
+	}
+	close(source)
+
+	result := slow.ToSlice()
+	if len(result) >= 10 || result[0] != 1 {
+		t.Errorf("Expected leading items with drops, got %v", result)
+	}
+}
+
+func TestMulticastDropOldest(t *testing.T) {
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	broadcaster := functools.Multicast(stream, functools.SlowDropOldest)
+	slow := broadcaster.Subscribe(2)
+	broadcaster.Start()
+
+	for i := 1; i <= 10; i++ {
+		source <- i
+	}
+	close(source)
+
+	// Older items are evicted, so the most recent item always survives
+	result := slow.ToSlice()
+	if len(result) >= 10 || result[len(result)-1] != 10 {
+		t.Errorf("Expected trailing items with drops, got %v", result)
+	}
+}
+
+func TestMulticastUnbounded(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	broadcaster := functools.Multicast(functools.Streamify(items), functools.SlowUnbounded)
+	slow := broadcaster.Subscribe(1)
+	broadcaster.Start()
+
+	// Give the source time to run ahead of the idle subscriber
+	time.Sleep(10 * time.Millisecond)
+
+	result := slow.ToSlice()
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+
+	// Subscribing after the source finished yields an empty stream
+	if late := broadcaster.Subscribe(1).ToSlice(); len(late) != 0 {
+		t.Errorf("Expected empty stream for late subscriber, got %v", late)
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	functools "github.com/felipegenef/functools"
 )
@@ -202,6 +203,30 @@ func TestBufferedStreamFilter(t *testing.T) {
 	}
 }
 
+func TestBufferedStreamFilterNot(t *testing.T) {
+	items := []int{1, 2, 3, 4}
+	stream := functools.StreamifyWithBuffer(items, 2)
+
+	result := stream.FilterNot(func(x int) bool { return x%2 == 0 }).ToSlice()
+	expected := []int{1, 3}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBufferedStreamToSortedSlice(t *testing.T) {
+	items := []int{5, 3, 4, 1, 2}
+	stream := functools.StreamifyWithBuffer(items, 2)
+
+	result := stream.ToSortedSlice(func(a, b int) bool { return a < b })
+	expected := []int{1, 2, 3, 4, 5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestBufferedStreamToStream(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.StreamifyWithBuffer(items, 2)
@@ -214,3 +239,31 @@ func TestBufferedStreamToStream(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
 }
+
+func TestBufferedStreamDepth(t *testing.T) {
+	items := []int{1, 2, 3}
+	buffered := functools.StreamifyWithBuffer(items, 5)
+
+	// Wait for the producer to fill the channel buffer
+	deadline := time.Now().Add(time.Second)
+	for buffered.Depth() != len(items) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth := buffered.Depth(); depth != len(items) {
+		t.Errorf("Expected depth %d, got %d", len(items), depth)
+	}
+
+	buffered.ToSlice()
+	if depth := buffered.Depth(); depth != 0 {
+		t.Errorf("Expected depth 0, got %d", depth)
+	}
+}
+
+func TestBufferedStreamCapacity(t *testing.T) {
+	buffered := functools.StreamifyWithBuffer([]int{1, 2, 3}, 5)
+
+	if capacity := buffered.Capacity(); capacity != 5 {
+		t.Errorf("Expected capacity 5, got %d", capacity)
+	}
+	buffered.ToSlice()
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	functools "github.com/felipegenef/functools"
+)
+
+func TestMapMapValues(t *testing.T) {
+	prices := map[string]int{"apple": 100, "pear": 250}
+
+	result := functools.MapMapValues(prices, func(cents int) float64 { return float64(cents) / 100 })
+	expected := map[string]float64{"apple": 1, "pear": 2.5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMapMapKeys(t *testing.T) {
+	stock := map[string]int{"apple": 3, "pear": 5}
+
+	result := functools.MapMapKeys(stock, strings.ToUpper)
+	expected := map[string]int{"APPLE": 3, "PEAR": 5}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test colliding keys keep a single entry
+	collided := functools.MapMapKeys(map[string]int{"a": 1, "A": 2}, strings.ToLower)
+	if len(collided) != 1 {
+		t.Errorf("Expected a single entry, got %v", collided)
+	}
+}
+
+type pair = struct {
+	Key   string
+	Value int
+}
+
+func TestToPairsRoundTrip(t *testing.T) {
+	stock := map[string]int{"pear": 5, "apple": 3, "fig": 1}
+
+	sorted := functools.ToPairs(stock).Sort(func(a, b pair) bool { return a.Key < b.Key })
+	keys := []string{}
+	sorted.ForEach(func(p pair) { keys = append(keys, p.Key) })
+	expectedKeys := []string{"apple", "fig", "pear"}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected %v, got %v", expectedKeys, keys)
+	}
+
+	result := functools.FromPairs(sorted)
+	if !reflect.DeepEqual(result, stock) {
+		t.Errorf("Expected %v, got %v", stock, result)
+	}
+}
+
+func TestFromPairsLastWins(t *testing.T) {
+	pairs := functools.Slicefy([]pair{{"a", 1}, {"b", 2}, {"a", 3}})
+
+	result := functools.FromPairs(pairs)
+	expected := map[string]int{"a": 3, "b": 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	functools "github.com/felipegenef/functools"
+)
+
+func TestPrefetchNext(t *testing.T) {
+	it := functools.Streamify([]int{1, 2, 3}).Prefetch(2)
+	defer it.Close()
+
+	var result []int
+	for v, ok := it.Next(); ok; v, ok = it.Next() {
+		result = append(result, v)
+	}
+	expected := []int{1, 2, 3}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestPrefetchReadsAhead(t *testing.T) {
+	produced := make(chan int, 10)
+	stream := functools.CreateStream(func(ch chan int) {
+		for i := 1; i <= 10; i++ {
+			ch <- i
+			produced <- i
+		}
+	})
+
+	it := stream.Prefetch(3)
+	defer it.Close()
+
+	// Without any Next calls the producer still runs ahead to fill the buffer
+	deadline := time.Now().Add(time.Second)
+	for len(produced) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(produced); n < 3 {
+		t.Errorf("Expected at least 3 prefetched items, got %d", n)
+	}
+}
+
+func TestPrefetchClose(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream := functools.CreateCancellableStream(producer)
+
+	it := stream.Pipe(func(x int) any { return x }).Prefetch(1)
+	if v, ok := it.Next(); !ok || v != 0 {
+		t.Errorf("Expected 0, got %v (ok=%v)", v, ok)
+	}
+	it.Close()
+
+	if _, ok := it.Next(); ok {
+		t.Errorf("Expected Next to report false after Close")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after Close")
+	}
+}
+
+func TestPrefetchCloseWhileWaiting(t *testing.T) {
+	// The upstream never sends, so the prefetch goroutine is waiting on it when closed
+	stopped := make(chan struct{})
+	stream := functools.CreateCancellableStream(func(ch chan int, done <-chan struct{}) {
+		defer close(stopped)
+		<-done
+	})
+
+	it := stream.Prefetch(2)
+	it.Close()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after Close")
+	}
+	if _, ok := it.Next(); ok {
+		t.Errorf("Expected Next to report false after Close")
+	}
+}
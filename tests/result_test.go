@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	functools "github.com/felipegenef/functools"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errInvalid   = errors.New("invalid")
+)
+
+func TestDematerialize(t *testing.T) {
+	results := []functools.Result[int]{{Value: 1}, {Value: 2}, {Err: errInvalid}, {Value: 3}}
+
+	values, failure := functools.Dematerialize(functools.Streamify(results))
+
+	result := values.ToSlice()
+	expected := []int{1, 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if !errors.Is(failure(), errInvalid) {
+		t.Errorf("Expected %v, got %v", errInvalid, failure())
+	}
+}
+
+func TestDematerializeReleasesSource(t *testing.T) {
+	// CreateStream can't be stopped, so the items after the error are drained
+	finished := make(chan struct{})
+	stream := functools.CreateStream(func(ch chan int) {
+		defer close(finished)
+		for i := 1; i <= 5; i++ {
+			ch <- i
+		}
+	})
+	failOn2 := func(x int) ([]int, error) {
+		if x == 2 {
+			return nil, errInvalid
+		}
+		return []int{x}, nil
+	}
+
+	values, failure := functools.Dematerialize(functools.ConcatMapErr(stream, failOn2))
+
+	result := values.ToSlice()
+	expected := []int{1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if !errors.Is(failure(), errInvalid) {
+		t.Errorf("Expected %v, got %v", errInvalid, failure())
+	}
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the source to finish")
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	isTransient := func(err error) bool { return errors.Is(err, errTransient) }
+	// Every retry starts over from a fresh stream
+	source := functools.Slicefy([]functools.Result[int]{{Value: 1}, {Value: 2}, {Value: 3}}).ToStream
+	initial := functools.Streamify([]functools.Result[int]{{Value: 1}, {Value: 2}, {Err: errTransient}})
+
+	values, failure := functools.RetryIf(initial, isTransient, 2, source)
+
+	result := values.ToSlice()
+	expected := []int{1, 2, 1, 2, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if failure() != nil {
+		t.Errorf("Expected a successful retry, got %v", failure())
+	}
+}
+
+func TestRetryIfNonRetryable(t *testing.T) {
+	isTransient := func(err error) bool { return errors.Is(err, errTransient) }
+	source := functools.Slicefy([]functools.Result[int]{{Value: 99}}).ToStream
+	initial := functools.Streamify([]functools.Result[int]{{Value: 1}, {Err: errInvalid}, {Value: 2}})
+
+	values, failure := functools.RetryIf(initial, isTransient, 3, source)
+
+	result := values.ToSlice()
+	expected := []int{1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if !errors.Is(failure(), errInvalid) {
+		t.Errorf("Expected %v without retrying, got %v", errInvalid, failure())
+	}
+}
+
+func TestRetryIfExhausted(t *testing.T) {
+	isTransient := func(err error) bool { return errors.Is(err, errTransient) }
+	// Each attempt emits a marker before failing again, so the output counts the retries
+	source := functools.Slicefy([]functools.Result[int]{{Value: 9}, {Err: errTransient}}).ToStream
+	initial := functools.Streamify([]functools.Result[int]{{Err: errTransient}})
+
+	values, failure := functools.RetryIf(initial, isTransient, 2, source)
+
+	result := values.ToSlice()
+	expected := []int{9, 9}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if !errors.Is(failure(), errTransient) {
+		t.Errorf("Expected %v after 2 retries, got %v", errTransient, failure())
+	}
+}
+
+func TestSplitResults(t *testing.T) {
+	results := []functools.Result[int]{{Value: 1}, {Err: errInvalid}, {Value: 2}, {Err: errTransient}, {Value: 3}}
+
+	values, errs := functools.SplitResults(functools.Streamify(results))
+
+	// Both outputs are consumed concurrently
+	var collected []error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		collected = errs.ToSlice()
+	}()
+	result := values.ToSlice()
+	<-done
+
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	expectedErrs := []error{errInvalid, errTransient}
+	if !reflect.DeepEqual(collected, expectedErrs) {
+		t.Errorf("Expected %v, got %v", expectedErrs, collected)
+	}
+}
+
+func TestPipeWithDLQ(t *testing.T) {
+	stream := functools.Streamify([]string{"1", "two", "3", "four"})
+
+	results, deadLetters := functools.PipeWithDLQ(stream, strconv.Atoi)
+
+	var failed []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		deadLetters.ForEach(func(letter struct {
+			Item string
+			Err  error
+		}) {
+			if letter.Err == nil {
+				t.Errorf("Expected an error for %q", letter.Item)
+			}
+			failed = append(failed, letter.Item)
+		})
+	}()
+	parsed := results.ToSlice()
+	<-done
+
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(parsed, expected) {
+		t.Errorf("Expected %v, got %v", expected, parsed)
+	}
+	expectedFailed := []string{"two", "four"}
+	if !reflect.DeepEqual(failed, expectedFailed) {
+		t.Errorf("Expected %v, got %v", expectedFailed, failed)
+	}
+}
+
+func TestConcatMapErr(t *testing.T) {
+	stream := functools.Streamify([]int{2, -1, 1})
+
+	result := functools.ConcatMapErr(stream, func(x int) ([]string, error) {
+		if x < 0 {
+			return nil, errInvalid
+		}
+		pages := []string{}
+		for page := 1; page <= x; page++ {
+			pages = append(pages, strconv.Itoa(x)+"-"+strconv.Itoa(page))
+		}
+		return pages, nil
+	}).ToSlice()
+	expected := []functools.Result[string]{
+		{Value: "2-1"},
+		{Value: "2-2"},
+		{Err: errInvalid},
+		{Value: "1-1"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
@@ -1,7 +1,14 @@
 package tests
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	functools "github.com/felipegenef/functools"
@@ -30,6 +37,21 @@ func TestIterableFilter(t *testing.T) {
 	}
 }
 
+func TestIterableFilterNot(t *testing.T) {
+	items := []int{1, 2, 3, 4}
+	iter := functools.Slicefy(items)
+
+	isEven := func(x int) bool { return x%2 == 0 }
+	expected := []int{1, 3}
+
+	if result := iter.FilterNot(isEven).ToSlice(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if result := iter.RejectBy(isEven).ToSlice(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestIterableMap(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.Slicefy(items)
@@ -43,6 +65,62 @@ func TestIterableMap(t *testing.T) {
 	}
 }
 
+func TestIterableClone(t *testing.T) {
+	items := []int{1, 2, 3}
+	clone := functools.Slicefy(items).Clone()
+
+	items[0] = 100
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(clone.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, clone.ToSlice())
+	}
+}
+
+func TestIterableDeepClone(t *testing.T) {
+	items := [][]int{{1, 2}, {3}}
+	clone := functools.Slicefy(items).DeepClone(func(row []int) []int {
+		return append([]int{}, row...)
+	})
+
+	// Mutating nested data in the clone must not touch the original
+	clone.ToSlice()[0][0] = 100
+	expected := [][]int{{1, 2}, {3}}
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %v, got %v", expected, items)
+	}
+}
+
+func TestIterableApplyParallel(t *testing.T) {
+	var items []int
+	for i := 0; i < 100; i++ {
+		items = append(items, i)
+	}
+	iter := functools.Slicefy(items)
+
+	result := iter.ApplyParallel(4, func(x int) int { return x * 2 }).ToSlice()
+	for i, v := range result {
+		if v != i*2 {
+			t.Fatalf("Expected %d at index %d, got %d", i*2, i, v)
+		}
+	}
+
+	// Test non-positive workers defaults to NumCPU
+	result = iter.ApplyParallel(0, func(x int) int { return x + 1 }).ToSlice()
+	if len(result) != len(items) || result[99] != 100 {
+		t.Errorf("Expected all items incremented, got %v", result)
+	}
+}
+
+func TestIterableApplyIf(t *testing.T) {
+	iter := functools.Slicefy([]string{"Go", "rust", "ZIG", "c"})
+
+	result := iter.ApplyIf(func(s string) bool { return len(s) > 1 }, strings.ToLower).ToSlice()
+	expected := []string{"go", "rust", "zig", "c"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestIterableReduce(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.Slicefy(items)
@@ -55,6 +133,27 @@ func TestIterableReduce(t *testing.T) {
 	}
 }
 
+func TestIterableReduce1(t *testing.T) {
+	items := []int{3, 1, 4, 1, 5}
+	iter := functools.Slicefy(items)
+
+	min, ok := iter.Reduce1(func(acc, item int) int {
+		if item < acc {
+			return item
+		}
+		return acc
+	})
+	if !ok || min != 1 {
+		t.Errorf("Expected (1, true), got (%d, %v)", min, ok)
+	}
+
+	// Test empty iterable
+	_, ok = functools.Slicefy([]int{}).Reduce1(func(acc, item int) int { return acc + item })
+	if ok {
+		t.Errorf("Expected false for empty iterable, got true")
+	}
+}
+
 func TestIterableFind(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.Slicefy(items)
@@ -71,6 +170,32 @@ func TestIterableFind(t *testing.T) {
 	}
 }
 
+func TestIterableFindLast(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 4, 3, 6, 5})
+
+	result, ok := iter.FindLast(func(x int) bool { return x%2 == 0 })
+	if !ok || result != 6 {
+		t.Errorf("Expected 6, got %v (ok=%v)", result, ok)
+	}
+
+	// Test when element is not found
+	result, ok = iter.FindLast(func(x int) bool { return x > 10 })
+	if ok || result != 0 {
+		t.Errorf("Expected zero value and false, got %v (ok=%v)", result, ok)
+	}
+}
+
+func TestIterableFindLastIndex(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 4, 3, 6, 5})
+
+	if index := iter.FindLastIndex(func(x int) bool { return x%2 == 0 }); index != 3 {
+		t.Errorf("Expected 3, got %d", index)
+	}
+	if index := iter.FindLastIndex(func(x int) bool { return x > 10 }); index != -1 {
+		t.Errorf("Expected -1, got %d", index)
+	}
+}
+
 func TestIterableSome(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.Slicefy(items)
@@ -114,6 +239,126 @@ func TestIterableSort(t *testing.T) {
 	}
 }
 
+func TestIterableSortInPlace(t *testing.T) {
+	items := []int{4, 3, 2, 1}
+	iter := functools.Slicefy(items)
+
+	sorted := iter.SortInPlace(func(a, b int) bool { return a < b })
+	expected := []int{1, 2, 3, 4}
+
+	if sorted != iter {
+		t.Errorf("Expected SortInPlace to return the receiver")
+	}
+	// The original slice is mutated
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %v, got %v", expected, items)
+	}
+}
+
+func TestIterableReverseInPlace(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	iter := functools.Slicefy(items)
+
+	iter.ReverseInPlace()
+	expected := []int{5, 4, 3, 2, 1}
+
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("Expected %v, got %v", expected, items)
+	}
+}
+
+func BenchmarkIterableSort(b *testing.B) {
+	items := make([]int, 100000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range items {
+			items[j] = len(items) - j
+		}
+		functools.Slicefy(items).Sort(func(a, b int) bool { return a < b })
+	}
+}
+
+func BenchmarkIterableSortInPlace(b *testing.B) {
+	items := make([]int, 100000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := range items {
+			items[j] = len(items) - j
+		}
+		functools.Slicefy(items).SortInPlace(func(a, b int) bool { return a < b })
+	}
+}
+
+func TestIterablePadRight(t *testing.T) {
+	items := []string{"a", "b"}
+	iter := functools.Slicefy(items)
+
+	result := iter.PadRight(4, "").ToSlice()
+	expected := []string{"a", "b", "", ""}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Already long enough: returned as an independent copy
+	result = iter.PadRight(1, "").ToSlice()
+	result[0] = "z"
+	if !reflect.DeepEqual(items, []string{"a", "b"}) {
+		t.Errorf("Expected the original items to be untouched, got %v", items)
+	}
+}
+
+func TestIterablePadLeft(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2})
+
+	result := iter.PadLeft(5, 0).ToSlice()
+	expected := []int{0, 0, 0, 1, 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	result = iter.PadLeft(2, 0).ToSlice()
+	expected = []int{1, 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestIterableTakeLast(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	iter := functools.Slicefy(items)
+
+	cases := map[int][]int{2: {4, 5}, 0: {}, -1: {}, 10: {1, 2, 3, 4, 5}}
+	for n, expected := range cases {
+		if result := iter.TakeLast(n).ToSlice(); !reflect.DeepEqual(result, expected) {
+			t.Errorf("TakeLast(%d): expected %v, got %v", n, expected, result)
+		}
+	}
+
+	// The result is an independent copy
+	iter.TakeLast(2).ToSlice()[0] = 0
+	if items[3] != 4 {
+		t.Errorf("Expected the original items to be untouched, got %v", items)
+	}
+}
+
+func TestIterableDropLast(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	iter := functools.Slicefy(items)
+
+	cases := map[int][]int{2: {1, 2, 3}, 0: {1, 2, 3, 4, 5}, -1: {1, 2, 3, 4, 5}, 10: {}}
+	for n, expected := range cases {
+		if result := iter.DropLast(n).ToSlice(); !reflect.DeepEqual(result, expected) {
+			t.Errorf("DropLast(%d): expected %v, got %v", n, expected, result)
+		}
+	}
+
+	// Appending to the result must not overwrite the dropped items
+	_ = append(iter.DropLast(2).ToSlice(), 0)
+	if items[3] != 4 {
+		t.Errorf("Expected the original items to be untouched, got %v", items)
+	}
+}
+
 func TestIterableConcat(t *testing.T) {
 	items1 := []int{1, 2}
 	items2 := []int{3, 4}
@@ -143,6 +388,93 @@ func TestIterableForEach(t *testing.T) {
 	}
 }
 
+func TestIterablePartition(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	calls := 0
+	even, odd := iter.Partition(func(x int) bool {
+		calls++
+		return x%2 == 0
+	})
+
+	if expected := []int{2, 4}; !reflect.DeepEqual(even.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, even.ToSlice())
+	}
+	if expected := []int{1, 3, 5}; !reflect.DeepEqual(odd.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, odd.ToSlice())
+	}
+	if calls != 5 {
+		t.Errorf("Expected the predicate to run once per item, got %d calls", calls)
+	}
+}
+
+func TestIterableProcessBatches(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	var batches [][]int
+	var progress []string
+	err := iter.ProcessBatches(2, func(batch []int) error {
+		batches = append(batches, batch)
+		return nil
+	}, func(done, total int) {
+		progress = append(progress, fmt.Sprintf("%d/%d", done, total))
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedBatches := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(batches, expectedBatches) {
+		t.Errorf("Expected %v, got %v", expectedBatches, batches)
+	}
+	expectedProgress := []string{"2/5", "4/5", "5/5"}
+	if !reflect.DeepEqual(progress, expectedProgress) {
+		t.Errorf("Expected %v, got %v", expectedProgress, progress)
+	}
+}
+
+func TestIterableProcessBatchesStopsOnError(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+	failure := errors.New("bad batch")
+
+	calls := 0
+	var progress []int
+	err := iter.ProcessBatches(2, func(batch []int) error {
+		calls++
+		if batch[0] == 3 {
+			return failure
+		}
+		return nil
+	}, func(done, total int) { progress = append(progress, done) })
+
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 batches to be processed, got %d", calls)
+	}
+	if !reflect.DeepEqual(progress, []int{2}) {
+		t.Errorf("Expected progress [2], got %v", progress)
+	}
+}
+
+func TestIterableForEachUntil(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	iter := functools.Slicefy(items)
+
+	var visited []int
+	iter.ForEachUntil(func(x int) bool {
+		visited = append(visited, x)
+		return x < 3
+	})
+
+	// Stops at the element that returned false and skips the remainder
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Errorf("Expected %v, got %v", expected, visited)
+	}
+}
+
 func TestIterableSlice(t *testing.T) {
 	items := []int{1, 2, 3, 4, 5}
 	iter := functools.Slicefy(items)
@@ -162,6 +494,85 @@ func TestIterableSlice(t *testing.T) {
 	}
 }
 
+func TestIterableOrElse(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3})
+	defaults := []int{0}
+
+	result := iter.OrElse(defaults).ToSlice()
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test fallback when the filtered result is empty
+	result = iter.Filter(func(x int) bool { return x > 5 }).OrElse(defaults).ToSlice()
+	if !reflect.DeepEqual(result, defaults) {
+		t.Errorf("Expected %v, got %v", defaults, result)
+	}
+}
+
+func TestIterableOrElseGet(t *testing.T) {
+	calls := 0
+	fallback := func() []int {
+		calls++
+		return []int{0}
+	}
+
+	result := functools.Slicefy([]int{1}).OrElseGet(fallback).ToSlice()
+	if !reflect.DeepEqual(result, []int{1}) || calls != 0 {
+		t.Errorf("Expected [1] without calling fallback, got %v after %d calls", result, calls)
+	}
+
+	result = functools.Slicefy([]int{}).OrElseGet(fallback).ToSlice()
+	if !reflect.DeepEqual(result, []int{0}) || calls != 1 {
+		t.Errorf("Expected [0] after one fallback call, got %v after %d calls", result, calls)
+	}
+}
+
+func TestIterableSwap(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+	iter := functools.Slicefy(items)
+
+	result := iter.Swap(0, 2).ToSlice()
+	expected := []string{"c", "b", "a", "d"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test out-of-range indices return an unchanged copy
+	result = iter.Swap(0, 10).ToSlice()
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+	result[0] = "z"
+	if items[0] != "a" {
+		t.Errorf("Expected original to remain unchanged, got %v", items)
+	}
+}
+
+func TestIterableMove(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	iter := functools.Slicefy(items)
+
+	result := iter.Move(1, 3).ToSlice()
+	expected := []string{"a", "c", "d", "b", "e"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	result = iter.Move(4, 0).ToSlice()
+	expected = []string{"e", "a", "b", "c", "d"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test out-of-range indices return an unchanged copy
+	result = iter.Move(-1, 2).ToSlice()
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+}
+
 func TestIterableToStream(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	iter := functools.Slicefy(items)
@@ -187,3 +598,846 @@ func TestIterableToBufferedStream(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
 }
+
+func TestCombinations(t *testing.T) {
+	iter := functools.Slicefy([]string{"a", "b", "c"})
+
+	result := functools.Combinations(iter, 2).ToSlice()
+	expected := [][]string{{"a", "b"}, {"a", "c"}, {"b", "c"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test k larger than the input
+	result = functools.Combinations(iter, 4).ToSlice()
+	if len(result) != 0 {
+		t.Errorf("Expected no combinations, got %v", result)
+	}
+}
+
+func TestPermutations(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3})
+
+	result := functools.Permutations(iter).ToSlice()
+	expected := [][]int{{1, 2, 3}, {1, 3, 2}, {2, 1, 3}, {2, 3, 1}, {3, 1, 2}, {3, 2, 1}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestGroupBySorted(t *testing.T) {
+	items := []string{"pear", "fig", "apple", "kiwi", "plum", "banana"}
+	iter := functools.Slicefy(items)
+
+	groups, keys := functools.GroupBySorted(iter, func(s string) int { return len(s) })
+
+	expectedKeys := []int{3, 4, 5, 6}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, keys)
+	}
+
+	expected := map[int][]string{
+		3: {"fig"},
+		4: {"pear", "kiwi", "plum"},
+		5: {"apple"},
+		6: {"banana"},
+	}
+	for _, k := range keys {
+		if result := groups[k].ToSlice(); !reflect.DeepEqual(result, expected[k]) {
+			t.Errorf("Expected group %d to be %v, got %v", k, expected[k], result)
+		}
+	}
+}
+
+func TestScanFold(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4})
+
+	steps, total := functools.ScanFold(iter, func(acc string, item int) string {
+		return acc + string(rune('0'+item))
+	}, ">")
+
+	expected := []string{">1", ">12", ">123", ">1234"}
+	if !reflect.DeepEqual(steps.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, steps.ToSlice())
+	}
+	if total != ">1234" {
+		t.Errorf("Expected >1234, got %s", total)
+	}
+
+	// Test empty iterable returns the initial value
+	steps, total = functools.ScanFold(functools.Slicefy([]int{}), func(acc string, item int) string {
+		return acc
+	}, ">")
+	if len(steps.ToSlice()) != 0 || total != ">" {
+		t.Errorf("Expected no steps and >, got %v and %s", steps.ToSlice(), total)
+	}
+}
+
+func TestFlattenIterables(t *testing.T) {
+	first := functools.Slicefy([]int{1, 2})
+	second := functools.Slicefy([]int{3})
+	third := functools.Slicefy([]int{4, 5})
+
+	result := functools.FlattenIterables(first, nil, second, third).ToSlice()
+	expected := []int{1, 2, 3, 4, 5}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// The inputs must not be affected by later writes to the result
+	result[0] = 100
+	if first.ToSlice()[0] != 1 {
+		t.Errorf("Expected input to remain unchanged, got %v", first.ToSlice())
+	}
+}
+
+func TestZipToMap(t *testing.T) {
+	keys := functools.Slicefy([]string{"a", "b", "c"})
+	values := functools.Slicefy([]int{1, 2})
+
+	// Test mismatched lengths stop at the shorter input
+	result := functools.ZipToMap(keys, values)
+	expected := map[string]int{"a": 1, "b": 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test last value wins on duplicate keys
+	keys = functools.Slicefy([]string{"a", "b", "a"})
+	values = functools.Slicefy([]int{1, 2, 3})
+	result = functools.ZipToMap(keys, values)
+	expected = map[string]int{"a": 3, "b": 2}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMapCollectErrors(t *testing.T) {
+	iter := functools.Slicefy([]string{"1", "x", "3", "y"})
+
+	results, errs := functools.MapCollectErrors(iter, strconv.Atoi)
+
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(results.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, results.ToSlice())
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "item 1:") || !strings.HasPrefix(errs[1].Error(), "item 3:") {
+		t.Errorf("Expected errors to carry positions, got %v", errs)
+	}
+	if !errors.Is(errs[0], strconv.ErrSyntax) {
+		t.Errorf("Expected wrapped strconv.ErrSyntax, got %v", errs[0])
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	iter := functools.Slicefy([]int{4, -2, 7, 3})
+
+	result := functools.Aggregate(iter)
+	expected := functools.Summary[int]{Count: 4, Sum: 12, Min: -2, Max: 7, Avg: 3}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	// Test empty iterable
+	empty := functools.Aggregate(functools.Slicefy([]float64{}))
+	if empty != (functools.Summary[float64]{}) {
+		t.Errorf("Expected zero summary, got %+v", empty)
+	}
+}
+
+func TestChunkStream(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	result := functools.ChunkStream(iter, 2).ToSlice()
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test empty iterable produces no chunks
+	empty := functools.ChunkStream(functools.Slicefy([]int{}), 2).ToSlice()
+	if len(empty) != 0 {
+		t.Errorf("Expected no chunks, got %v", empty)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	// Expand day ranges into the individual days, skipping days already covered
+	ranges := functools.Slicefy([][2]int{{1, 3}, {2, 5}, {8, 9}})
+
+	result := functools.Expand(ranges, func(days []int, r [2]int) []int {
+		start := r[0]
+		if len(days) > 0 && days[len(days)-1] >= start {
+			start = days[len(days)-1] + 1
+		}
+		for day := start; day <= r[1]; day++ {
+			days = append(days, day)
+		}
+		return days
+	}, nil).ToSlice()
+
+	expected := []int{1, 2, 3, 4, 5, 8, 9}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestScan(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4})
+
+	result := functools.Scan(iter, func(acc, item int) int { return acc + item }, 0).ToSlice()
+	expected := []int{1, 3, 6, 10}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestScanIndexed(t *testing.T) {
+	iter := functools.Slicefy([]float64{10, 10, 10})
+
+	// Weight each item by its position
+	result := functools.ScanIndexed(iter, func(acc float64, index int, item float64) float64 {
+		return acc + float64(index+1)*item
+	}, 0).ToSlice()
+	expected := []float64{10, 30, 60}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestIsMonotonic(t *testing.T) {
+	increasing := functools.Slicefy([]int{1, 2, 2, 3})
+	strict := functools.Slicefy([]int{1, 2, 3})
+	decreasing := functools.Slicefy([]int{3, 3, 1})
+	mixed := functools.Slicefy([]int{1, 3, 2})
+	empty := functools.Slicefy([]int{})
+	single := functools.Slicefy([]int{7})
+
+	tests := []struct {
+		name     string
+		check    func() bool
+		expected bool
+	}{
+		{"increasing", func() bool { return functools.IsMonotonicIncreasing(increasing) }, true},
+		{"increasing not strict", func() bool { return functools.IsStrictlyIncreasing(increasing) }, false},
+		{"strict", func() bool { return functools.IsStrictlyIncreasing(strict) }, true},
+		{"decreasing", func() bool { return functools.IsMonotonicDecreasing(decreasing) }, true},
+		{"decreasing not strict", func() bool { return functools.IsStrictlyDecreasing(decreasing) }, false},
+		{"mixed increasing", func() bool { return functools.IsMonotonicIncreasing(mixed) }, false},
+		{"mixed decreasing", func() bool { return functools.IsMonotonicDecreasing(mixed) }, false},
+		{"empty", func() bool { return functools.IsStrictlyIncreasing(empty) }, true},
+		{"single", func() bool { return functools.IsStrictlyDecreasing(single) }, true},
+	}
+	for _, tt := range tests {
+		if result := tt.check(); result != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, result)
+		}
+	}
+}
+
+func TestPartitionDuplicates(t *testing.T) {
+	iter := functools.Slicefy([]string{"a", "b", "a", "c", "b", "a"})
+
+	unique, duplicates := functools.PartitionDuplicates(iter)
+
+	expectedUnique := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(unique.ToSlice(), expectedUnique) {
+		t.Errorf("Expected %v, got %v", expectedUnique, unique.ToSlice())
+	}
+	expectedDuplicates := []string{"a", "b", "a"}
+	if !reflect.DeepEqual(duplicates.ToSlice(), expectedDuplicates) {
+		t.Errorf("Expected %v, got %v", expectedDuplicates, duplicates.ToSlice())
+	}
+}
+
+func TestFoldMap(t *testing.T) {
+	type point struct{ X, Y int }
+	type box struct{ MinX, MinY, MaxX, MaxY int }
+
+	points := functools.Slicefy([]point{{1, 5}, {-2, 3}, {4, -1}})
+	empty := box{MinX: math.MaxInt, MinY: math.MaxInt, MaxX: math.MinInt, MaxY: math.MinInt}
+
+	// Bounding box of the points: map each point to a box and union the boxes
+	result := functools.FoldMap(points, func(p point) box {
+		return box{p.X, p.Y, p.X, p.Y}
+	}, func(a, b box) box {
+		return box{min(a.MinX, b.MinX), min(a.MinY, b.MinY), max(a.MaxX, b.MaxX), max(a.MaxY, b.MaxY)}
+	}, empty)
+
+	expected := box{MinX: -2, MinY: -1, MaxX: 4, MaxY: 5}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestCartesianProduct(t *testing.T) {
+	sizes := functools.Slicefy([]string{"S", "M"})
+	colors := functools.Slicefy([]int{1, 2, 3})
+
+	format := func(first string, second int) string { return fmt.Sprintf("%s%d", first, second) }
+	expected := []string{"S1", "S2", "S3", "M1", "M2", "M3"}
+
+	var result []string
+	functools.CartesianProduct(sizes, colors).ForEach(func(p struct {
+		First  string
+		Second int
+	}) {
+		result = append(result, format(p.First, p.Second))
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// The lazy variant yields the same ordering
+	result = nil
+	functools.CartesianProductStream(sizes, colors).ForEach(func(p struct {
+		First  string
+		Second int
+	}) {
+		result = append(result, format(p.First, p.Second))
+	})
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBinarySearch(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	users := functools.Slicefy([]user{{1, "ann"}, {4, "bob"}, {9, "cid"}})
+	byID := func(a, b user) int { return a.ID - b.ID }
+
+	index, found := functools.BinarySearch(users, user{ID: 4}, byID)
+	if index != 1 || !found {
+		t.Errorf("Expected (1, true), got (%d, %v)", index, found)
+	}
+
+	// Test not found returns the insertion point
+	index, found = functools.BinarySearch(users, user{ID: 5}, byID)
+	if index != 2 || found {
+		t.Errorf("Expected (2, false), got (%d, %v)", index, found)
+	}
+}
+
+func TestBinarySearchOrdered(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 3, 5, 7})
+
+	tests := []struct {
+		target        int
+		expectedIndex int
+		expectedFound bool
+	}{
+		{5, 2, true},
+		{0, 0, false},
+		{4, 2, false},
+		{8, 4, false},
+	}
+	for _, tt := range tests {
+		index, found := functools.BinarySearchOrdered(iter, tt.target)
+		if index != tt.expectedIndex || found != tt.expectedFound {
+			t.Errorf("Target %d: expected (%d, %v), got (%d, %v)", tt.target, tt.expectedIndex, tt.expectedFound, index, found)
+		}
+	}
+}
+
+func TestChunkOverlap(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5, 6, 7})
+
+	result := functools.ChunkOverlap(iter, 3, 1).ToSlice()
+	expected := [][]int{{1, 2, 3}, {3, 4, 5}, {5, 6, 7}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test the final partial chunk
+	result = functools.ChunkOverlap(iter, 4, 2).ToSlice()
+	expected = [][]int{{1, 2, 3, 4}, {3, 4, 5, 6}, {5, 6, 7}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test zero overlap is a plain chunking
+	result = functools.ChunkOverlap(iter, 3, 0).ToSlice()
+	expected = [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test invalid overlap
+	result = functools.ChunkOverlap(iter, 3, 3).ToSlice()
+	if len(result) != 0 {
+		t.Errorf("Expected no chunks, got %v", result)
+	}
+}
+
+func TestGroupMap(t *testing.T) {
+	type sale struct {
+		Region string
+		Amount int
+	}
+	sales := functools.Slicefy([]sale{{"north", 10}, {"south", 5}, {"north", 7}, {"east", 1}, {"south", 3}})
+
+	result := functools.GroupMap(sales, func(s sale) string { return s.Region }, func(group []sale) int {
+		total := 0
+		for _, s := range group {
+			total += s.Amount
+		}
+		return total
+	})
+
+	expected := map[string]int{"north": 17, "south": 8, "east": 1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestUnfold(t *testing.T) {
+	// Fibonacci numbers below 50
+	fib := functools.Unfold([2]int{0, 1}, func(state [2]int) (int, [2]int, bool) {
+		return state[0], [2]int{state[1], state[0] + state[1]}, state[0] < 50
+	})
+
+	expected := []int{0, 1, 1, 2, 3, 5, 8, 13, 21, 34}
+	if !reflect.DeepEqual(fib.ToSlice(), expected) {
+		t.Errorf("Expected %v, got %v", expected, fib.ToSlice())
+	}
+
+	// Test stopping immediately yields an empty iterable
+	empty := functools.Unfold(0, func(n int) (int, int, bool) { return n, n, false })
+	if len(empty.ToSlice()) != 0 {
+		t.Errorf("Expected empty iterable, got %v", empty.ToSlice())
+	}
+}
+
+func TestWeightedSample(t *testing.T) {
+	weights := map[string]float64{"a": 1, "b": 9, "zero": 0, "negative": -3}
+	iter := functools.Slicefy([]string{"a", "b", "zero", "negative"})
+	weight := func(s string) float64 { return weights[s] }
+	rng := rand.New(rand.NewSource(42))
+
+	// Heavier items are picked first far more often
+	firstPicks := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		sample := functools.WeightedSample(iter, weight, 1, rng).ToSlice()
+		firstPicks[sample[0]]++
+	}
+	if firstPicks["b"] < 800 || firstPicks["zero"] != 0 || firstPicks["negative"] != 0 {
+		t.Errorf("Expected b to dominate and non-positive weights to be skipped, got %v", firstPicks)
+	}
+
+	// Test n larger than the positive-weight items returns each of them once
+	sample := functools.WeightedSample(iter, weight, 10, rng).ToSlice()
+	sort.Strings(sample)
+	expected := []string{"a", "b"}
+	if !reflect.DeepEqual(sample, expected) {
+		t.Errorf("Expected %v, got %v", expected, sample)
+	}
+}
+
+func TestEachCons(t *testing.T) {
+	// (1..10).each_cons(3).to_a from the Ruby docs
+	var items []int
+	for i := 1; i <= 10; i++ {
+		items = append(items, i)
+	}
+	result := functools.EachCons(functools.Slicefy(items), 3).ToSlice()
+	expected := [][]int{{1, 2, 3}, {2, 3, 4}, {3, 4, 5}, {4, 5, 6}, {5, 6, 7}, {6, 7, 8}, {7, 8, 9}, {8, 9, 10}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// [1, 2].each_cons(3).to_a == []
+	short := functools.EachCons(functools.Slicefy([]int{1, 2}), 3).ToSlice()
+	if len(short) != 0 {
+		t.Errorf("Expected no runs, got %v", short)
+	}
+}
+
+func TestRank(t *testing.T) {
+	scores := functools.Slicefy([]int{70, 95, 80, 95, 60})
+	higherFirst := func(a, b int) bool { return a > b }
+
+	format := func(ranked []struct {
+		Item int
+		Rank int
+	}) []string {
+		var result []string
+		for _, r := range ranked {
+			result = append(result, fmt.Sprintf("%d:%d", r.Rank, r.Item))
+		}
+		return result
+	}
+
+	result := format(functools.Rank(scores, higherFirst).ToSlice())
+	expected := []string{"1:95", "1:95", "3:80", "4:70", "5:60"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	result = format(functools.DenseRank(scores, higherFirst).ToSlice())
+	expected = []string{"1:95", "1:95", "2:80", "3:70", "4:60"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	type menu struct {
+		ID     int
+		Parent int
+		Label  string
+	}
+	rows := functools.Slicefy([]menu{
+		{1, 0, "File"},
+		{2, 1, "Open"},
+		{3, 1, "Save"},
+		{4, 0, "Edit"},
+		{5, 3, "Save As"},
+		{6, 99, "Orphan"},
+	})
+
+	roots := functools.BuildTree(rows, func(m menu) int { return m.ID }, func(m menu) (int, bool) {
+		return m.Parent, m.Parent != 0
+	})
+
+	// render flattens the forest into indented labels
+	var render func(nodes []*functools.TreeNode[menu], depth int) []string
+	render = func(nodes []*functools.TreeNode[menu], depth int) []string {
+		var lines []string
+		for _, node := range nodes {
+			lines = append(lines, strings.Repeat("-", depth)+node.Item.Label)
+			lines = append(lines, render(node.Children, depth+1)...)
+		}
+		return lines
+	}
+
+	// The orphan is treated as a root
+	expected := []string{"File", "-Open", "-Save", "--Save As", "Edit", "Orphan"}
+	if result := render(roots, 0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestBuildTreeCycle(t *testing.T) {
+	type node struct {
+		ID, Parent int
+	}
+	// 2 -> 3 -> 4 -> 2 form a cycle, and 5 hangs below it
+	rows := functools.Slicefy([]node{{1, 0}, {3, 2}, {2, 4}, {4, 3}, {5, 4}})
+
+	roots := functools.BuildTree(rows, func(n node) int { return n.ID }, func(n node) (int, bool) {
+		return n.Parent, n.Parent != 0
+	})
+
+	var render func(nodes []*functools.TreeNode[node], depth int) []string
+	render = func(nodes []*functools.TreeNode[node], depth int) []string {
+		var lines []string
+		for _, n := range nodes {
+			lines = append(lines, strings.Repeat("-", depth)+strconv.Itoa(n.Item.ID))
+			lines = append(lines, render(n.Children, depth+1)...)
+		}
+		return lines
+	}
+
+	// The cycle is broken at 3, its earliest item, which becomes an extra root
+	expected := []string{"1", "3", "-4", "--2", "--5"}
+	if result := render(roots, 0); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestSplitBy(t *testing.T) {
+	codes := functools.Slicefy([]int{200, 404, 201, 500, 302, 503, 204})
+
+	result := functools.SplitBy(codes, func(code int) int { return code / 100 })
+	expected := map[int][]int{
+		2: {200, 201, 204},
+		3: {302},
+		4: {404},
+		5: {500, 503},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestInterleaveIterables(t *testing.T) {
+	high := functools.Slicefy([]string{"h1", "h2", "h3", "h4"})
+	medium := functools.Slicefy([]string{"m1"})
+	low := functools.Slicefy([]string{"l1", "l2"})
+
+	result := functools.InterleaveIterables(high, medium, nil, low).ToSlice()
+	expected := []string{"h1", "m1", "l1", "h2", "l2", "h3", "h4"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestGroupByTwo(t *testing.T) {
+	type sale struct {
+		Region string
+		Month  string
+		Amount int
+	}
+	sales := functools.Slicefy([]sale{
+		{"north", "jan", 10},
+		{"north", "feb", 5},
+		{"south", "jan", 7},
+		{"north", "jan", 3},
+		{"south", "jan", 1},
+	})
+
+	result := functools.GroupByTwo(sales,
+		func(s sale) string { return s.Region },
+		func(s sale) string { return s.Month },
+		func(total int, s sale) int { return total + s.Amount },
+		func() int { return 0 },
+	)
+
+	expected := map[string]map[string]int{
+		"north": {"jan": 13, "feb": 5},
+		"south": {"jan": 8},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMovingSum(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	result := functools.MovingSum(iter, 3).ToSlice()
+	expected := []int{6, 9, 12}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Windows larger than the input produce nothing
+	result = functools.MovingSum(iter, 6).ToSlice()
+	if len(result) != 0 {
+		t.Errorf("Expected empty result, got %v", result)
+	}
+}
+
+func TestMovingAverage(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	result := functools.MovingAverage(iter, 2).ToSlice()
+	expected := []float64{1.5, 2.5, 3.5, 4.5}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestNormalizeMinMax(t *testing.T) {
+	iter := functools.Slicefy([]int{10, 20, 15, 30})
+
+	result := functools.NormalizeMinMax(iter).ToSlice()
+	expected := []float64{0, 0.5, 0.25, 1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// A constant input has no range and maps to zeros
+	result = functools.NormalizeMinMax(functools.Slicefy([]int{7, 7, 7})).ToSlice()
+	expected = []float64{0, 0, 0}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestClamp(t *testing.T) {
+	iter := functools.Slicefy([]int{-5, 0, 128, 255, 300})
+
+	result := functools.Clamp(iter, 0, 255).ToSlice()
+	expected := []int{0, 0, 128, 255, 255}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Swapped bounds behave the same
+	result = functools.Clamp(iter, 255, 0).ToSlice()
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestRunningMinMax(t *testing.T) {
+	iter := functools.Slicefy([]int{3, 1, 4, 1, 5})
+
+	result := functools.RunningMinMax(iter).ToSlice()
+	expected := []struct{ Min, Max int }{{3, 3}, {1, 3}, {1, 4}, {1, 4}, {1, 5}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	empty := functools.RunningMinMax(functools.Slicefy([]int{})).ToSlice()
+	if len(empty) != 0 {
+		t.Errorf("Expected empty result, got %v", empty)
+	}
+}
+
+func BenchmarkMovingSum(b *testing.B) {
+	items := make([]int, 100000)
+	for i := range items {
+		items[i] = i
+	}
+	iter := functools.Slicefy(items)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		functools.MovingSum(iter, 1000)
+	}
+}
+
+func BenchmarkMovingSumNaive(b *testing.B) {
+	items := make([]int, 100000)
+	for i := range items {
+		items[i] = i
+	}
+	size := 1000
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		// Re-sum every window from scratch, with no copies
+		sums := make([]int, len(items)-size+1)
+		for j := range sums {
+			for _, x := range items[j : j+size] {
+				sums[j] += x
+			}
+		}
+	}
+}
+
+func TestMap(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3})
+
+	result := functools.Map(iter, strconv.Itoa).ToSlice()
+	expected := []string{"1", "2", "3"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestFold(t *testing.T) {
+	type order struct {
+		Customer string
+		Total    float64
+	}
+	orders := functools.Slicefy([]order{{"ann", 10}, {"bob", 5}, {"ann", 2.5}})
+
+	result := functools.Fold(orders, func(acc map[string]float64, o order) map[string]float64 {
+		acc[o.Customer] += o.Total
+		return acc
+	}, map[string]float64{})
+	expected := map[string]float64{"ann": 12.5, "bob": 5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestFlatMap(t *testing.T) {
+	iter := functools.Slicefy([]string{"a b", "", "c d e"})
+
+	result := functools.FlatMap(iter, strings.Fields).ToSlice()
+	expected := []string{"a", "b", "c", "d", "e"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	iter := functools.Slicefy([][]int{{1, 2}, {}, {3}, {4, 5}})
+
+	result := functools.Flatten(iter).ToSlice()
+	expected := []int{1, 2, 3, 4, 5}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	iter := functools.Slicefy([]int{1, 2, 3, 4, 5})
+
+	result := functools.Chunk(iter, 2).ToSlice()
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	empty := functools.Chunk(functools.Slicefy([]int{}), 3).ToSlice()
+	if len(empty) != 0 {
+		t.Errorf("Expected no chunks, got %v", empty)
+	}
+}
+
+func TestZip(t *testing.T) {
+	names := functools.Slicefy([]string{"a", "b", "c"})
+	ages := functools.Slicefy([]int{1, 2})
+
+	result := functools.Zip(names, ages).ToSlice()
+	expected := []functools.Pair[string, int]{{First: "a", Second: 1}, {First: "b", Second: 2}}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestZipWith(t *testing.T) {
+	prices := functools.Slicefy([]float64{2.5, 10, 4})
+	quantities := functools.Slicefy([]int{4, 1, 0, 7})
+
+	result := functools.ZipWith(prices, quantities, func(p float64, q int) float64 { return p * float64(q) }).ToSlice()
+	expected := []float64{10, 10, 0}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	ages := []int{1, 2, 3}
+
+	firsts, seconds := functools.Unzip(functools.Zip(functools.Slicefy(names), functools.Slicefy(ages)))
+
+	if result := firsts.ToSlice(); !reflect.DeepEqual(result, names) {
+		t.Errorf("Expected %v, got %v", names, result)
+	}
+	if result := seconds.ToSlice(); !reflect.DeepEqual(result, ages) {
+		t.Errorf("Expected %v, got %v", ages, result)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	iter := functools.Slicefy([]string{"apple", "avocado", "banana", "blueberry", "cherry"})
+
+	result := functools.GroupBy(iter, func(s string) byte { return s[0] })
+	expected := map[byte][]string{
+		'a': {"apple", "avocado"},
+		'b': {"banana", "blueberry"},
+		'c': {"cherry"},
+	}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestDistinct(t *testing.T) {
+	iter := functools.Slicefy([]string{"b", "a", "b", "c", "a"})
+
+	result := functools.Distinct(iter).ToSlice()
+	expected := []string{"b", "a", "c"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
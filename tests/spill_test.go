@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"reflect"
+	"testing"
+
+	functools "github.com/felipegenef/functools"
+)
+
+func TestToSpillingIterable(t *testing.T) {
+	var items []int
+	for i := 0; i < 100; i++ {
+		items = append(items, i)
+	}
+
+	spilled, cleanup := functools.Streamify(items).ToSpillingIterable(10)
+	defer func() {
+		if err := cleanup(); err != nil {
+			t.Errorf("Expected cleanup to succeed, got %v", err)
+		}
+	}()
+
+	if spilled.Len() != len(items) {
+		t.Errorf("Expected %d items, got %d", len(items), spilled.Len())
+	}
+
+	var result []int
+	if err := spilled.ForEach(func(x int) { result = append(result, x) }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+
+	// The collection can be iterated again, here as a stream
+	stream, failure := spilled.ToStream()
+	result = stream.ToSlice()
+	if failure() != nil || !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v without error, got %v and %v", items, result, failure())
+	}
+}
+
+func TestToSpillingIterableInMemory(t *testing.T) {
+	items := []string{"a", "b", "c"}
+
+	spilled, cleanup := functools.Streamify(items).ToSpillingIterable(10)
+	defer cleanup()
+
+	var result []string
+	if err := spilled.ForEach(func(s string) { result = append(result, s) }); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+}
+
+// countEven can be written by callers because the spilling iterable type is exported
+func countEven(spilled *functools.SpillingIterable[int]) int {
+	stream, _ := spilled.ToStream()
+	return len(stream.Filter(func(x int) bool { return x%2 == 0 }).ToSlice())
+}
+
+func TestSpillingIterableStreamOperators(t *testing.T) {
+	var items []int
+	for i := 0; i < 50; i++ {
+		items = append(items, i)
+	}
+
+	spilled, cleanup := functools.Streamify(items).ToSpillingIterable(5)
+	defer cleanup()
+
+	if count := countEven(spilled); count != 25 {
+		t.Errorf("Expected 25 even items, got %d", count)
+	}
+}
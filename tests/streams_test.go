@@ -1,8 +1,16 @@
 package tests
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	functools "github.com/felipegenef/functools"
 )
@@ -66,6 +74,46 @@ func TestStreamFilter(t *testing.T) {
 	}
 }
 
+func TestStreamApplyIf(t *testing.T) {
+	stream := functools.Streamify([]int{-2, 3, -5, 7})
+
+	result := stream.ApplyIf(func(x int) bool { return x < 0 }, func(x int) int { return -x }).ToSlice()
+	expected := []int{2, 3, 5, 7}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamApplyIfStopsProducer(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream := functools.CreateCancellableStream(producer)
+
+	isOdd := func(x int) bool { return x%2 == 1 }
+	negate := func(x int) int { return -x }
+	result := stream.ApplyIf(isOdd, negate).WithDeadline(20 * time.Millisecond).ToSlice()
+	if len(result) < 2 || result[0] != 0 || result[1] != -1 {
+		t.Errorf("Expected the items to start with 0 and -1, got %v", result)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after the deadline")
+	}
+}
+
+func TestStreamFilterNot(t *testing.T) {
+	items := []int{1, 2, 3, 4}
+	stream := functools.Streamify(items)
+
+	result := stream.FilterNot(func(x int) bool { return x%2 == 0 }).ToSlice()
+	expected := []int{1, 3}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestStreamForEach(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	stream := functools.Streamify(items)
@@ -81,6 +129,262 @@ func TestStreamForEach(t *testing.T) {
 	}
 }
 
+func TestStreamForEachBatch(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	stream := functools.Streamify(items)
+
+	var batches [][]int
+	err := stream.ForEachBatch(3, func(batch []int) error {
+		batches = append(batches, batch)
+		return nil
+	})
+
+	expected := [][]int{{1, 2, 3}, {4, 5, 6}, {7}}
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("Expected %v, got %v", expected, batches)
+	}
+}
+
+func TestStreamForEachBatchError(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	stream := functools.Streamify(items)
+	failure := errors.New("sink unavailable")
+
+	calls := 0
+	err := stream.ForEachBatch(2, func(batch []int) error {
+		calls++
+		if calls == 2 {
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("Expected %v, got %v", failure, err)
+	}
+
+	// The remaining items are left in the stream for a retry
+	rest := stream.ToSlice()
+	expected := []int{5, 6, 7}
+	if !reflect.DeepEqual(rest, expected) {
+		t.Errorf("Expected %v, got %v", expected, rest)
+	}
+}
+
+func TestStreamPartition(t *testing.T) {
+	stream := functools.Streamify([]int{1, 2, 3, 4, 5, 6})
+
+	even, odd := stream.Partition(func(x int) bool { return x%2 == 0 })
+
+	// Both outputs have to be drained concurrently
+	var odds []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		odds = odd.ToSlice()
+	}()
+	evens := even.ToSlice()
+	<-done
+
+	if expected := []int{2, 4, 6}; !reflect.DeepEqual(evens, expected) {
+		t.Errorf("Expected %v, got %v", expected, evens)
+	}
+	if expected := []int{1, 3, 5}; !reflect.DeepEqual(odds, expected) {
+		t.Errorf("Expected %v, got %v", expected, odds)
+	}
+}
+
+func TestStreamFilterPar(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	stream := functools.Streamify(items)
+
+	// Earlier items take longer, so an unordered filter would reorder them
+	result := stream.FilterPar(4, func(x int) bool {
+		time.Sleep(time.Duration(len(items)-x) * time.Millisecond)
+		return x%2 == 0
+	}).ToSlice()
+	expected := []int{2, 4, 6, 8}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamFilterParUnordered(t *testing.T) {
+	stream := functools.Streamify([]int{1, 2, 3, 4, 5, 6, 7, 8})
+
+	result := stream.FilterParUnordered(0, func(x int) bool { return x%2 == 0 }).ToSlice()
+	sort.Ints(result)
+	expected := []int{2, 4, 6, 8}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+// endlessProducer returns a generator for CreateCancellableStream that sends 0, 1, 2, ...
+// (pausing between items when pause is set) until it is asked to stop, and a channel
+// closed once it has returned.
+func endlessProducer(pause time.Duration) (func(ch chan int, done <-chan struct{}), <-chan struct{}) {
+	stopped := make(chan struct{})
+	return func(ch chan int, done <-chan struct{}) {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case ch <- i:
+			case <-done:
+				return
+			}
+			if pause > 0 {
+				time.Sleep(pause)
+			}
+		}
+	}, stopped
+}
+
+func TestStreamToSliceWithin(t *testing.T) {
+	producer, stopped := endlessProducer(20 * time.Millisecond)
+	stream := functools.CreateCancellableStream(producer)
+
+	result := stream.ToSliceWithin(50 * time.Millisecond)
+	if len(result) < 1 || len(result) > 4 || result[0] != 0 {
+		t.Errorf("Expected the first few items, got %v", result)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop at the deadline")
+	}
+
+	// A stream that closes early returns everything
+	result = functools.Streamify([]int{1, 2, 3}).ToSliceWithin(time.Second)
+	expected := []int{1, 2, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamDefaultIfEmpty(t *testing.T) {
+	result := functools.Streamify([]int{1, 2, 3}).
+		Filter(func(x int) bool { return x > 5 }).
+		DefaultIfEmpty(-1).
+		ToSlice()
+	expected := []int{-1}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Non-empty streams pass through untouched
+	result = functools.Streamify([]int{1, 2, 3}).DefaultIfEmpty(-1).ToSlice()
+	expected = []int{1, 2, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestCreateCancellableStream(t *testing.T) {
+	stream := functools.CreateCancellableStream(func(ch chan int, done <-chan struct{}) {
+		for _, v := range []int{1, 2, 3} {
+			select {
+			case ch <- v:
+			case <-done:
+				return
+			}
+		}
+	})
+
+	result := stream.ToSlice()
+	expected := []int{1, 2, 3}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamFirstOrDefault(t *testing.T) {
+	if result := functools.Streamify([]int{4, 5, 6}).FirstOrDefault(-1); result != 4 {
+		t.Errorf("Expected 4, got %d", result)
+	}
+	if result := functools.Streamify([]int{}).FirstOrDefault(-1); result != -1 {
+		t.Errorf("Expected -1, got %d", result)
+	}
+}
+
+func TestStreamFirstOrDefaultStopsProducer(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream := functools.CreateCancellableStream(producer)
+
+	if result := stream.Pipe(func(x int) any { return x + 10 }).FirstOrDefault(-1); result != 10 {
+		t.Errorf("Expected 10, got %v", result)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after the first item")
+	}
+}
+
+func TestStreamFirstOrDefaultStopsProducerThroughStages(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream, _ := functools.CreateCancellableStream(producer).Track()
+
+	if result := stream.EveryNth(2).FirstOrDefault(-1); result != 0 {
+		t.Errorf("Expected 0, got %v", result)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after the first item")
+	}
+}
+
+func TestStreamFirstOrDefaultDrainsUnstoppableProducer(t *testing.T) {
+	// CreateStream can't be stopped, so the rest of the stream is drained instead of
+	// leaving the producer blocked
+	finished := make(chan struct{})
+	stream := functools.CreateStream(func(ch chan int) {
+		defer close(finished)
+		for i := 1; i <= 100; i++ {
+			ch <- i
+		}
+	})
+
+	double := func(x int) int { return x * 2 }
+	isOdd := func(x int) bool { return x%2 == 1 }
+	if result := stream.ApplyIf(isOdd, double).FirstOrDefault(-1); result != 2 {
+		t.Errorf("Expected 2, got %v", result)
+	}
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to finish")
+	}
+}
+
+func TestStreamLastOrDefault(t *testing.T) {
+	if result := functools.Streamify([]int{4, 5, 6}).LastOrDefault(-1); result != 6 {
+		t.Errorf("Expected 6, got %d", result)
+	}
+	if result := functools.Streamify([]int{}).LastOrDefault(-1); result != -1 {
+		t.Errorf("Expected -1, got %d", result)
+	}
+}
+
+func TestStreamToSortedSlice(t *testing.T) {
+	items := []string{"pear", "fig", "kiwi", "apple", "date"}
+	stream := functools.Streamify(items)
+
+	// Sort by length; equal lengths keep arrival order
+	result := stream.ToSortedSlice(func(a, b string) bool { return len(a) < len(b) })
+	expected := []string{"fig", "pear", "kiwi", "date", "apple"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
 func TestStreamToBufferedStream(t *testing.T) {
 	items := []int{1, 2, 3, 4}
 	stream := functools.Streamify(items)
@@ -93,3 +397,1099 @@ func TestStreamToBufferedStream(t *testing.T) {
 		t.Errorf("Expected %v, got %v", expected, result)
 	}
 }
+
+func TestStreamAsyncBoundary(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+	maxBuffer := 3
+	boundary := functools.Streamify(items).AsyncBoundary(maxBuffer)
+
+	var result []int
+	boundary.ForEach(func(x int) {
+		// Slow consumer lets the producer fill the buffer
+		time.Sleep(2 * time.Millisecond)
+		if depth := boundary.Depth(); depth > maxBuffer {
+			t.Errorf("Expected depth <= %d, got %d", maxBuffer, depth)
+		}
+		result = append(result, x)
+	})
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+	if depth := boundary.Depth(); depth != 0 {
+		t.Errorf("Expected empty buffer after consumption, got %d", depth)
+	}
+}
+
+func TestRoute(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	stream := functools.Streamify(items)
+
+	// 7 classifies to "other" and must be dropped
+	routes := functools.Route(stream, func(x int) string {
+		switch {
+		case x == 7:
+			return "other"
+		case x%2 == 0:
+			return "even"
+		default:
+			return "odd"
+		}
+	}, []string{"even", "odd"})
+
+	if len(routes) != 2 {
+		t.Fatalf("Expected 2 routes, got %d", len(routes))
+	}
+
+	var wg sync.WaitGroup
+	results := make(map[string][]int)
+	var mu sync.Mutex
+	for key, route := range routes {
+		wg.Add(1)
+		go func(key string, collect func() []int) {
+			defer wg.Done()
+			values := collect()
+			mu.Lock()
+			results[key] = values
+			mu.Unlock()
+		}(key, route.ToSlice)
+	}
+	wg.Wait()
+
+	expected := map[string][]int{"even": {2, 4, 6}, "odd": {1, 3, 5}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestStreamGate(t *testing.T) {
+	control := make(chan bool)
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	gated := stream.Gate(control)
+
+	received := make(chan int)
+	go func() {
+		defer close(received)
+		gated.ForEach(func(x int) { received <- x })
+	}()
+
+	source <- 1
+	if v := <-received; v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+
+	// Pause the gate: the next item must not pass through
+	control <- false
+	go func() { source <- 2 }()
+	select {
+	case v := <-received:
+		t.Errorf("Expected gate to be paused, got %d", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	// Resume: the held item is released promptly
+	control <- true
+	select {
+	case v := <-received:
+		if v != 2 {
+			t.Errorf("Expected 2, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected gate to resume")
+	}
+
+	close(source)
+	if _, ok := <-received; ok {
+		t.Errorf("Expected gated stream to close")
+	}
+}
+
+func TestStreamGateClosesWhilePaused(t *testing.T) {
+	control := make(chan bool)
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	gated := stream.Gate(control)
+
+	received := make(chan int)
+	go func() {
+		defer close(received)
+		gated.ForEach(func(x int) { received <- x })
+	}()
+
+	source <- 1
+	if v := <-received; v != 1 {
+		t.Errorf("Expected 1, got %d", v)
+	}
+	control <- false
+	close(source)
+
+	// The output closes without waiting for the gate to reopen
+	select {
+	case v, ok := <-received:
+		if ok {
+			t.Errorf("Expected the gated stream to close, got %d", v)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Expected the gated stream to close while paused")
+	}
+}
+
+func TestStreamConflate(t *testing.T) {
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	conflated := stream.Conflate(func(acc, next int) int { return acc + next })
+
+	// Nobody reads while these are produced, so they are folded together
+	for i := 1; i <= 4; i++ {
+		source <- i
+	}
+	close(source)
+
+	// The last item may still be in flight, but earlier ones must be folded
+	result := conflated.ToSlice()
+	sum := 0
+	for _, v := range result {
+		sum += v
+	}
+	if sum != 10 || len(result) > 2 {
+		t.Errorf("Expected conflated items summing to 10, got %v", result)
+	}
+}
+
+func TestStreamConflateKeepLatest(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	conflated := functools.Streamify(items).Conflate(func(_, next int) int { return next })
+
+	// A fast consumer may see every item, but the latest one is never lost
+	result := conflated.ToSlice()
+	if len(result) == 0 || result[len(result)-1] != 5 {
+		t.Errorf("Expected the last item to be 5, got %v", result)
+	}
+}
+
+func TestExpandRecursive(t *testing.T) {
+	tree := map[string][]string{
+		"root": {"a", "b"},
+		"a":    {"a1", "a2"},
+		"b":    {"b1"},
+	}
+
+	stream := functools.ExpandRecursive([]string{"root"}, func(node string) []string {
+		return tree[node]
+	}, nil)
+
+	result := stream.ToSlice()
+	expected := []string{"root", "a", "b", "a1", "a2", "b1"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestExpandRecursiveCancel(t *testing.T) {
+	done := make(chan struct{})
+	// Infinite expansion: every number expands into its successor
+	stream := functools.ExpandRecursive([]int{0}, func(n int) []int {
+		return []int{n + 1}
+	}, done)
+
+	var result []int
+	stream.ForEach(func(n int) {
+		result = append(result, n)
+		if n == 4 {
+			close(done)
+		}
+	})
+
+	// An item already offered when done closes may still be delivered
+	expected := []int{0, 1, 2, 3, 4}
+	if len(result) > len(expected)+1 || !reflect.DeepEqual(result[:len(expected)], expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestExpandRecursiveStopsWhenHalted(t *testing.T) {
+	var expanded atomic.Int64
+	// Infinite expansion with no done channel, bounded only by the consumer
+	stream := functools.ExpandRecursive([]int{0}, func(n int) []int {
+		expanded.Add(1)
+		return []int{n + 1}
+	}, nil)
+
+	result := stream.AtIndices(3).ToSlice()
+	expected := []int{3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	time.Sleep(10 * time.Millisecond)
+	before := expanded.Load()
+	time.Sleep(20 * time.Millisecond)
+	if after := expanded.Load(); after != before {
+		t.Errorf("Expected the traversal to stop, got %d more expansions", after-before)
+	}
+}
+
+func TestExpandRecursiveDistinct(t *testing.T) {
+	// Cyclic graph: a -> b -> c -> a
+	graph := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+		"c": {"a"},
+	}
+
+	stream := functools.ExpandRecursiveDistinct([]string{"a"}, func(node string) []string {
+		return graph[node]
+	}, func(node string) string { return node }, nil)
+
+	result := stream.ToSlice()
+	expected := []string{"a", "b", "c"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamWithDeadline(t *testing.T) {
+	// Long-running generator emitting every millisecond
+	stream := functools.CreateStream(func(ch chan int) {
+		for i := 0; i < 1000; i++ {
+			ch <- i
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	start := time.Now()
+	result := stream.WithDeadline(30 * time.Millisecond).ToSlice()
+	elapsed := time.Since(start)
+
+	if len(result) == 0 || len(result) >= 1000 {
+		t.Errorf("Expected a partial result, got %d items", len(result))
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Errorf("Expected stream to close near the deadline, took %v", elapsed)
+	}
+	for i, v := range result {
+		if v != i {
+			t.Fatalf("Expected items in order, got %v", result)
+		}
+	}
+}
+
+func TestStreamWithDeadlineStopsProducer(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream := functools.CreateCancellableStream(producer)
+
+	result := stream.Filter(func(x int) bool { return x%2 == 0 }).WithDeadline(20 * time.Millisecond).ToSlice()
+	if len(result) == 0 {
+		t.Errorf("Expected some items before the deadline")
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after the deadline")
+	}
+}
+
+func TestStreamWithDeadlineFinishesEarly(t *testing.T) {
+	items := []int{1, 2, 3}
+	result := functools.Streamify(items).WithDeadline(time.Second).ToSlice()
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+}
+
+func TestApproxFrequency(t *testing.T) {
+	var items []int
+	for i := 0; i < 100; i++ {
+		items = append(items, 7)
+	}
+	for i := 0; i < 200; i++ {
+		items = append(items, i)
+	}
+	stream := functools.Streamify(items)
+
+	frequency := functools.ApproxFrequency(stream, func(x int) uint64 { return uint64(x) }, 512, 4)
+
+	// Count-Min never undercounts, and with this width it stays close
+	if got := frequency(7); got < 101 || got > 110 {
+		t.Errorf("Expected an estimate near 101 for 7, got %d", got)
+	}
+	if got := frequency(150); got < 1 || got > 10 {
+		t.Errorf("Expected an estimate near 1 for 150, got %d", got)
+	}
+	if got := frequency(5000); got > 10 {
+		t.Errorf("Expected an estimate near 0 for unseen item, got %d", got)
+	}
+}
+
+func TestStreamProgress(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	reports := make(chan int, 10)
+
+	result := functools.Streamify(items).Progress(3, func(count int) {
+		reports <- count
+	}).ToSlice()
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+
+	// Reports arrive asynchronously: every 3 items plus the final total
+	var counts []int
+	for len(counts) < 3 {
+		select {
+		case count := <-reports:
+			counts = append(counts, count)
+		case <-time.After(time.Second):
+			t.Fatalf("Expected 3 reports, got %v", counts)
+		}
+	}
+	expected := []int{3, 6, 7}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
+
+func TestStreamProgressExactMultiple(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6}
+	reports := make(chan int, 10)
+
+	functools.Streamify(items).Progress(3, func(count int) {
+		reports <- count
+	}).ToSlice()
+
+	// The final total coincides with a periodic report and must not be repeated
+	var counts []int
+	timeout := time.After(100 * time.Millisecond)
+	for collecting := true; collecting; {
+		select {
+		case count := <-reports:
+			counts = append(counts, count)
+		case <-timeout:
+			collecting = false
+		}
+	}
+	expected := []int{3, 6}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+}
+
+func TestDistinctBloom(t *testing.T) {
+	var items []int
+	for i := 0; i < 1000; i++ {
+		items = append(items, i%500)
+	}
+	stream := functools.Streamify(items)
+
+	result := functools.DistinctBloom(stream, func(x int) uint64 { return uint64(x) }, 500, 0.001).ToSlice()
+
+	// Duplicates are always removed; only a handful of false positives may be lost
+	if len(result) > 500 || len(result) < 490 {
+		t.Errorf("Expected close to 500 unique items, got %d", len(result))
+	}
+	seen := make(map[int]bool)
+	for _, v := range result {
+		if seen[v] {
+			t.Fatalf("Expected no duplicates, got %d twice", v)
+		}
+		seen[v] = true
+	}
+}
+
+func TestStreamElapsed(t *testing.T) {
+	stream := functools.CreateStream(func(ch chan int) {
+		ch <- 1
+		time.Sleep(20 * time.Millisecond)
+		ch <- 2
+	})
+
+	result := functools.Elapsed(stream).ToSlice()
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(result))
+	}
+	if result[0].Value != 1 || result[0].Since != 0 {
+		t.Errorf("Expected first item 1 with zero gap, got %+v", result[0])
+	}
+	if result[1].Value != 2 || result[1].Since < 15*time.Millisecond {
+		t.Errorf("Expected second item 2 with a gap of about 20ms, got %+v", result[1])
+	}
+}
+
+func TestMergeByTimestamp(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return base.Add(time.Duration(seconds) * time.Second) }
+
+	first := functools.Streamify([]time.Time{at(1), at(4), at(6), at(9)})
+	second := functools.Streamify([]time.Time{at(2), at(3), at(7), at(8)})
+	third := functools.Streamify([]time.Time{at(5)})
+
+	// A lateness larger than the whole range holds everything until the sources close
+	merged := functools.MergeByTimestamp(func(t time.Time) time.Time { return t }, time.Hour, first, second, third)
+
+	result := merged.ToSlice()
+	expected := []time.Time{at(1), at(2), at(3), at(4), at(5), at(6), at(7), at(8), at(9)}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMergeByTimestampWatermark(t *testing.T) {
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	ts := func(seconds int) time.Time { return time.Unix(int64(seconds), 0) }
+	merged := functools.MergeByTimestamp(ts, 2*time.Second, stream)
+
+	received := make(chan int, 10)
+	go func() {
+		merged.ForEach(func(x int) { received <- x })
+		close(received)
+	}()
+
+	// 3 arrives slightly out of order and is still emitted before 4
+	for _, v := range []int{1, 4, 3, 6} {
+		source <- v
+	}
+	close(source)
+
+	var result []int
+	for v := range received {
+		result = append(result, v)
+	}
+	expected := []int{1, 3, 4, 6}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestShardByHash(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	shards := functools.ShardByHash(functools.Streamify(items), 3, func(x int) uint64 { return uint64(x) })
+
+	if len(shards) != 3 {
+		t.Fatalf("Expected 3 shards, got %d", len(shards))
+	}
+
+	results := make([][]int, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func(i int, collect func() []int) {
+			defer wg.Done()
+			results[i] = collect()
+		}(i, shard.ToSlice)
+	}
+	wg.Wait()
+
+	expected := [][]int{{3, 6, 9}, {1, 4, 7}, {2, 5, 8}}
+	if !reflect.DeepEqual(results, expected) {
+		t.Errorf("Expected %v, got %v", expected, results)
+	}
+}
+
+func TestStreamSampleOnInterval(t *testing.T) {
+	stream := functools.CreateStream(func(ch chan int) {
+		// A fast burst, a pause long enough for several ticks, then another burst
+		for i := 1; i <= 5; i++ {
+			ch <- i
+		}
+		time.Sleep(100 * time.Millisecond)
+		for i := 6; i <= 10; i++ {
+			ch <- i
+		}
+		time.Sleep(100 * time.Millisecond)
+	})
+
+	result := stream.SampleOnInterval(20 * time.Millisecond).ToSlice()
+
+	// Each burst collapses into its latest value, and idle ticks emit nothing
+	// (a tick landing mid-burst may add an extra intermediate sample)
+	if len(result) < 2 || len(result) > 4 || result[len(result)-1] != 10 {
+		t.Errorf("Expected bursts sampled down to their latest values, got %v", result)
+	}
+	if !slices.Contains(result, 5) {
+		t.Errorf("Expected the end of the first burst to be sampled, got %v", result)
+	}
+}
+
+func TestWithLatestFrom(t *testing.T) {
+	primarySource := make(chan int)
+	otherSource := make(chan string)
+	primary := functools.CreateStream(func(ch chan int) {
+		for v := range primarySource {
+			ch <- v
+		}
+	})
+	other := functools.CreateStream(func(ch chan string) {
+		for v := range otherSource {
+			ch <- v
+		}
+	})
+
+	combined := functools.WithLatestFrom(primary, other)
+	done := make(chan []string)
+	go func() {
+		var result []string
+		combined.ForEach(func(item struct {
+			Primary int
+			Latest  string
+		}) {
+			result = append(result, fmt.Sprintf("%d:%s", item.Primary, item.Latest))
+		})
+		done <- result
+	}()
+
+	// settle lets the operator observe the previous send before the next one
+	settle := func() { time.Sleep(10 * time.Millisecond) }
+
+	primarySource <- 1 // dropped: nothing seen on other yet
+	settle()
+	otherSource <- "a"
+	settle()
+	primarySource <- 2
+	primarySource <- 3
+	settle()
+	otherSource <- "b"
+	settle()
+	primarySource <- 4
+	close(primarySource)
+	close(otherSource)
+
+	result := <-done
+	expected := []string{"2:a", "3:a", "4:b"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestWithLatestFromReleasesSources(t *testing.T) {
+	producer, stopped := endlessProducer(time.Millisecond)
+	primary := functools.CreateCancellableStream(producer)
+	// other can't be stopped, so it is drained once the output is halted
+	finished := make(chan struct{})
+	other := functools.CreateStream(func(ch chan string) {
+		defer close(finished)
+		for i := 0; i < 100; i++ {
+			ch <- "a"
+		}
+	})
+
+	combined := functools.WithLatestFrom(primary, other)
+	first := combined.FirstOrDefault(struct {
+		Primary int
+		Latest  string
+	}{Primary: -1})
+	if first.Latest != "a" {
+		t.Errorf("Expected a, got %v", first.Latest)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the primary producer to stop")
+	}
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the other producer to finish")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	latencies := []int{-1, 0, 5, 9, 10, 50, 99, 100, 2500}
+	stream := functools.Streamify(latencies)
+
+	result := functools.Histogram(stream, []int{10, 0, 100})
+	expected := map[string]int{
+		"(-Inf,0)":   1,
+		"[0,10)":     3,
+		"[10,100)":   3,
+		"[100,+Inf)": 2,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamPace(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	rate := 10 * time.Millisecond
+
+	var arrivals []time.Time
+	var result []int
+	functools.Streamify(items).Pace(rate, 2).ForEach(func(x int) {
+		arrivals = append(arrivals, time.Now())
+		result = append(result, x)
+	})
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+	// The burst is spread out so consecutive items are at least rate apart
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < rate-time.Millisecond {
+			t.Errorf("Expected a gap of at least %v, got %v", rate, gap)
+		}
+	}
+}
+
+func TestStreamTokenBucket(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	start := time.Now()
+	var arrivals []time.Duration
+	var result []int
+	functools.Streamify(items).TokenBucket(50, 3).ForEach(func(x int) {
+		arrivals = append(arrivals, time.Since(start))
+		result = append(result, x)
+	})
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+	// 3 items pass as a burst, the remaining 2 wait 20ms each for a token
+	if arrivals[2] > 15*time.Millisecond {
+		t.Errorf("Expected the burst to pass immediately, third item took %v", arrivals[2])
+	}
+	if arrivals[4] < 35*time.Millisecond {
+		t.Errorf("Expected throttling after the burst, last item took %v", arrivals[4])
+	}
+}
+
+func TestStreamTokenBucketInvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -5, math.NaN()} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected a panic for rate %v", rate)
+				}
+			}()
+			functools.Streamify([]int{1}).TokenBucket(rate, 1)
+		}()
+	}
+}
+
+func TestDropLate(t *testing.T) {
+	ts := func(seconds int) time.Time { return time.Unix(int64(seconds), 0) }
+	stream := functools.Streamify([]int{10, 12, 9, 15, 11, 14, 20})
+
+	onTime, late := functools.DropLate(stream, ts, 3*time.Second)
+
+	var lateItems []int
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lateItems = late.ToSlice()
+	}()
+	onTimeItems := onTime.ToSlice()
+	<-done
+
+	// 11 is 4s behind 15 and dropped, while 14 is within the 3s allowance
+	expectedOnTime := []int{10, 12, 9, 15, 14, 20}
+	if !reflect.DeepEqual(onTimeItems, expectedOnTime) {
+		t.Errorf("Expected %v, got %v", expectedOnTime, onTimeItems)
+	}
+	expectedLate := []int{11}
+	if !reflect.DeepEqual(lateItems, expectedLate) {
+		t.Errorf("Expected %v, got %v", expectedLate, lateItems)
+	}
+}
+
+func TestWindowStats(t *testing.T) {
+	stream := functools.Streamify([]int{4, 1, 3, 6, 2, 5})
+
+	result := functools.WindowStats(stream, 3).ToSlice()
+	expected := []functools.Summary[int]{
+		{Count: 3, Sum: 8, Min: 1, Max: 4, Avg: 8.0 / 3},
+		{Count: 3, Sum: 10, Min: 1, Max: 6, Avg: 10.0 / 3},
+		{Count: 3, Sum: 11, Min: 2, Max: 6, Avg: 11.0 / 3},
+		{Count: 3, Sum: 13, Min: 2, Max: 6, Avg: 13.0 / 3},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+
+	// Test streams shorter than the window emit nothing
+	short := functools.WindowStats(functools.Streamify([]int{1, 2}), 3).ToSlice()
+	if len(short) != 0 {
+		t.Errorf("Expected no summaries, got %v", short)
+	}
+}
+
+func TestStreamAdaptiveBuffer(t *testing.T) {
+	var items []int
+	for i := 0; i < 50; i++ {
+		items = append(items, i)
+	}
+	buffered := functools.Streamify(items).AdaptiveBuffer(2, 16)
+
+	if capacity := buffered.Capacity(); capacity != 2 {
+		t.Errorf("Expected initial capacity 2, got %d", capacity)
+	}
+
+	// A slow consumer makes the producer hit the limit, growing the buffer
+	peak := 0
+	var result []int
+	buffered.ForEach(func(x int) {
+		time.Sleep(time.Millisecond)
+		capacity := buffered.Capacity()
+		peak = max(peak, capacity)
+		if capacity < 2 || capacity > 16 {
+			t.Errorf("Expected capacity within [2, 16], got %d", capacity)
+		}
+		result = append(result, x)
+	})
+
+	if !reflect.DeepEqual(result, items) {
+		t.Errorf("Expected %v, got %v", items, result)
+	}
+	if peak <= 2 {
+		t.Errorf("Expected the buffer to grow for a lagging consumer")
+	}
+	// Once the consumer catches up, the buffer shrinks again
+	if capacity := buffered.Capacity(); capacity >= peak {
+		t.Errorf("Expected capacity to shrink below %d, got %d", peak, capacity)
+	}
+}
+
+func TestChangesBy(t *testing.T) {
+	type reading struct {
+		Sensor string
+		Status string
+	}
+	stream := functools.Streamify([]reading{
+		{"s1", "ok"}, {"s2", "ok"}, {"s3", "fail"}, {"s4", "fail"}, {"s5", "ok"},
+	})
+
+	result := functools.ChangesBy(stream, func(r reading) string { return r.Status }).ToSlice()
+
+	// The full item that triggered each transition is emitted
+	expected := []reading{{"s1", "ok"}, {"s3", "fail"}, {"s5", "ok"}}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestJoinByKey(t *testing.T) {
+	type order struct {
+		ID    int
+		Total int
+	}
+	type payment struct {
+		OrderID int
+		Method  string
+	}
+	orders := functools.Streamify([]order{{1, 100}, {2, 50}, {3, 75}})
+	payments := functools.Streamify([]payment{{2, "card"}, {1, "cash"}, {4, "card"}})
+
+	joined := functools.JoinByKey(orders, func(o order) int { return o.ID },
+		payments, func(p payment) int { return p.OrderID }, time.Second)
+
+	var result []string
+	joined.ForEach(func(pair struct {
+		A order
+		B payment
+	}) {
+		result = append(result, fmt.Sprintf("%d:%s", pair.A.ID, pair.B.Method))
+	})
+	sort.Strings(result)
+
+	// Orders 3 and 4 have no counterpart and are dropped
+	expected := []string{"1:cash", "2:card"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestJoinByKeyWindowExpiry(t *testing.T) {
+	left := functools.CreateStream(func(ch chan string) {
+		ch <- "early"
+	})
+	right := functools.CreateStream(func(ch chan string) {
+		time.Sleep(50 * time.Millisecond)
+		ch <- "early"
+	})
+
+	identity := func(s string) string { return s }
+	joined := functools.JoinByKey(left, identity, right, identity, 10*time.Millisecond)
+
+	// The match arrives after the window expired, so nothing is joined
+	if result := joined.ToSlice(); len(result) != 0 {
+		t.Errorf("Expected no joined pairs, got %v", result)
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	stream := functools.Streamify([]string{"a", "b", "c", "d", "e"})
+
+	var items []string
+	var checkpoints []int
+	functools.Checkpoint(stream, 2).ForEach(func(record struct {
+		Item       string
+		Checkpoint bool
+		Offset     int
+	}) {
+		items = append(items, record.Item)
+		if record.Checkpoint {
+			checkpoints = append(checkpoints, record.Offset)
+		}
+	})
+
+	expectedItems := []string{"a", "b", "c", "d", "e"}
+	if !reflect.DeepEqual(items, expectedItems) {
+		t.Errorf("Expected %v, got %v", expectedItems, items)
+	}
+	expectedCheckpoints := []int{2, 4}
+	if !reflect.DeepEqual(checkpoints, expectedCheckpoints) {
+		t.Errorf("Expected checkpoints at %v, got %v", expectedCheckpoints, checkpoints)
+	}
+}
+
+func TestStreamAuditTime(t *testing.T) {
+	// A busy stream emitting every 5ms for about 100ms
+	stream := functools.CreateStream(func(ch chan int) {
+		for i := 1; i <= 20; i++ {
+			ch <- i
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+
+	result := stream.AuditTime(25 * time.Millisecond).ToSlice()
+
+	// Unlike a debounce it keeps emitting while the stream is busy, sampling trailing
+	// values rather than leading ones, and always ends with the final item
+	if len(result) < 2 || len(result) > 10 {
+		t.Errorf("Expected a few periodic samples, got %v", result)
+	}
+	if result[0] == 1 {
+		t.Errorf("Expected the first sample to be a trailing value, got %v", result)
+	}
+	if result[len(result)-1] != 20 {
+		t.Errorf("Expected the final item to be emitted, got %v", result)
+	}
+	if !slices.IsSorted(result) {
+		t.Errorf("Expected samples in order, got %v", result)
+	}
+}
+
+func TestStreamTrack(t *testing.T) {
+	source := make(chan int)
+	stream := functools.CreateStream(func(ch chan int) {
+		for v := range source {
+			ch <- v
+		}
+	})
+	tracked, latest := stream.Track()
+
+	if _, ok := latest(); ok {
+		t.Errorf("Expected no value before anything was forwarded")
+	}
+
+	received := make(chan int)
+	go func() {
+		defer close(received)
+		tracked.ForEach(func(x int) { received <- x })
+	}()
+
+	source <- 1
+	<-received
+	source <- 2
+	<-received
+	close(source)
+	<-received
+
+	if v, ok := latest(); !ok || v != 2 {
+		t.Errorf("Expected (2, true), got (%d, %v)", v, ok)
+	}
+}
+
+func TestCreateStreamWithOverflowDropNewest(t *testing.T) {
+	finished := make(chan struct{})
+	stream, dropped := functools.CreateStreamWithOverflow(func(ch chan int) {
+		defer close(finished)
+		for i := 1; i <= 10; i++ {
+			ch <- i
+		}
+	}, 3, functools.OverflowDropNewest)
+
+	// The generator completes without a consumer, dropping what doesn't fit
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the generator to never block")
+	}
+
+	result := stream.ToSlice()
+	if len(result)+dropped() != 10 {
+		t.Errorf("Expected kept and dropped items to add up to 10, got %v and %d dropped", result, dropped())
+	}
+	if dropped() == 0 || result[0] != 1 {
+		t.Errorf("Expected the newest items to be dropped, got %v", result)
+	}
+}
+
+func TestCreateStreamWithOverflowDropOldest(t *testing.T) {
+	finished := make(chan struct{})
+	stream, dropped := functools.CreateStreamWithOverflow(func(ch chan int) {
+		defer close(finished)
+		for i := 1; i <= 10; i++ {
+			ch <- i
+		}
+	}, 3, functools.OverflowDropOldest)
+	<-finished
+
+	result := stream.ToSlice()
+	if len(result)+dropped() != 10 || result[len(result)-1] != 10 {
+		t.Errorf("Expected the oldest items to be dropped, got %v and %d dropped", result, dropped())
+	}
+}
+
+func TestCreateStreamWithOverflowBlock(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6}
+	stream, dropped := functools.CreateStreamWithOverflow(func(ch chan int) {
+		for _, v := range items {
+			ch <- v
+		}
+	}, 2, functools.OverflowBlock)
+
+	result := stream.ToSlice()
+	if !reflect.DeepEqual(result, items) || dropped() != 0 {
+		t.Errorf("Expected %v with nothing dropped, got %v and %d dropped", items, result, dropped())
+	}
+}
+
+func TestStreamEveryNth(t *testing.T) {
+	stream := functools.Streamify([]int{0, 1, 2, 3, 4, 5, 6, 7})
+
+	result := stream.EveryNth(3).ToSlice()
+	expected := []int{0, 3, 6}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamAtIndices(t *testing.T) {
+	stream := functools.CreateStream(func(ch chan string) {
+		for i := 0; i < 100; i++ {
+			ch <- fmt.Sprintf("row%d", i)
+		}
+	})
+
+	// Indices may repeat or be out of order
+	result := stream.AtIndices(5, 0, 2, 2).ToSlice()
+	expected := []string{"row0", "row2", "row5"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestStreamAtIndicesStopsProducer(t *testing.T) {
+	producer, stopped := endlessProducer(0)
+	stream := functools.CreateCancellableStream(producer)
+
+	result := stream.AtIndices(1, 3).ToSlice()
+	expected := []int{1, 3}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Errorf("Expected the producer to stop after the last index")
+	}
+}
+
+func TestStreamInstrument(t *testing.T) {
+	// Each stage runs in its own goroutine, so the counters are atomic
+	var sourceItems, filteredItems atomic.Int64
+	var sourceTotal, filteredTotal atomic.Int64
+
+	result := functools.Streamify([]int{1, 2, 3, 4}).
+		Instrument("source", func() { sourceItems.Add(1) }, func(total int) { sourceTotal.Store(int64(total)) }).
+		Filter(func(x int) bool { return x%2 == 0 }).
+		Instrument("filtered", func() { filteredItems.Add(1) }, func(total int) { filteredTotal.Store(int64(total)) }).
+		ToSlice()
+
+	expected := []int{2, 4}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if sourceItems.Load() != 4 || sourceTotal.Load() != 4 {
+		t.Errorf("Expected 4 source items, got %d (total %d)", sourceItems.Load(), sourceTotal.Load())
+	}
+	if filteredItems.Load() != 2 || filteredTotal.Load() != 2 {
+		t.Errorf("Expected 2 filtered items, got %d (total %d)", filteredItems.Load(), filteredTotal.Load())
+	}
+}
+
+func TestGroupWithinCount(t *testing.T) {
+	stream := functools.Streamify([]int{1, 2, 3, 4, 5})
+
+	result := functools.GroupWithin(stream, 2, time.Hour).ToSlice()
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestGroupWithinIdle(t *testing.T) {
+	stream := functools.CreateStream(func(ch chan int) {
+		ch <- 1
+		ch <- 2
+		time.Sleep(100 * time.Millisecond)
+		ch <- 3
+		ch <- 4
+		time.Sleep(100 * time.Millisecond)
+		ch <- 5
+	})
+
+	result := functools.GroupWithin(stream, 10, 30*time.Millisecond).ToSlice()
+	expected := [][]int{{1, 2}, {3, 4}, {5}}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestFlatMapConcat(t *testing.T) {
+	stream := functools.Streamify([][]int{{1, 2}, {}, {3}, {4, 5, 6}})
+
+	// Each item yields its own inner stream, read to completion in order
+	result := functools.FlatMapConcat(stream, functools.Streamify[int]).ToSlice()
+	expected := []int{1, 2, 3, 4, 5, 6}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMapAccumulate(t *testing.T) {
+	stream := functools.Streamify([]string{"a", "b", "c"})
+
+	// Assign incrementing IDs to each item
+	result := functools.MapAccumulate(stream, 100, func(next int, item string) (int, string) {
+		return next + 1, fmt.Sprintf("%d:%s", next, item)
+	}).ToSlice()
+	expected := []string{"100:a", "101:b", "102:c"}
+
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}